@@ -0,0 +1,59 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterProjectDevelopmentStreamTemplate is a cluster-wide
+// ProjectDevelopmentStreamTemplate that ProjectDevelopmentStreams in any
+// namespace can use. Its project field is ignored. The developmentStreams
+// listed in its status are given as namespace/name.
+type ClusterProjectDevelopmentStreamTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProjectDevelopmentStreamTemplateSpec   `json:"spec,omitempty"`
+	Status ProjectDevelopmentStreamTemplateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterProjectDevelopmentStreamTemplateList contains a list of
+// ClusterProjectDevelopmentStreamTemplate
+type ClusterProjectDevelopmentStreamTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterProjectDevelopmentStreamTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(
+			GroupVersion,
+			&ClusterProjectDevelopmentStreamTemplate{},
+			&ClusterProjectDevelopmentStreamTemplateList{},
+		)
+		return nil
+	})
+}
@@ -39,7 +39,44 @@ type ProjectSpec struct {
 	Description string `json:"description,omitempty"`
 }
 
+// ProjectDevelopmentStreamSummary describes the state of a
+// ProjectDevelopmentStream that belongs to a Project
+type ProjectDevelopmentStreamSummary struct {
+	// The name of the ProjectDevelopmentStream
+	Name string `json:"name"`
+	// The status of the ProjectDevelopmentStream's Ready condition, Unknown if
+	// the condition is not set yet
+	Ready metav1.ConditionStatus `json:"ready"`
+	// The reason given in the ProjectDevelopmentStream's Ready condition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProjectStatus defines the observed state of Project
+// Conditions include:
+// - Ready (reasons: NoDevelopmentStreams, DevelopmentStreamsReady, DevelopmentStreamsNotReady, DevelopmentStreamsFailed)
+type ProjectStatus struct {
+	// Represents the observations of a Project's current state.
+	// Known .status.conditions.type are: "Ready"
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// The ProjectDevelopmentStreams that belong to this Project, sorted by
+	// name
+	// +optional
+	DevelopmentStreams []ProjectDevelopmentStreamSummary `json:"developmentStreams,omitempty"`
+	// The number of ProjectDevelopmentStreams that are ready
+	// +optional
+	ReadyStreams int32 `json:"readyStreams,omitempty"`
+	// The number of ProjectDevelopmentStreams that failed to become ready
+	// +optional
+	FailedStreams int32 `json:"failedStreams,omitempty"`
+}
+
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 
 // Project represents a Konflux project for organizing related development streams.
 // No custom labels or annotations on Project alter controller behavior.
@@ -47,7 +84,8 @@ type Project struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ProjectSpec `json:"spec,omitempty"`
+	Spec   ProjectSpec   `json:"spec,omitempty"`
+	Status ProjectStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
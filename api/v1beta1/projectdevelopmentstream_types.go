@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -27,23 +28,147 @@ import (
 // Provide a value for a variable specified in an associated
 // ProjectDevelopmentStreamTemplate
 // Use values to customize generated resources per development stream.
+// +kubebuilder:validation:XValidation:rule="!has(self.valueFrom) || !has(self.value) || size(self.value) == 0",message="value and valueFrom are mutually exclusive"
 type ProjectDevelopmentStreamSpecTemplateValue struct {
 	// The name of the template variable to provide a value for
 	Name string `json:"name"`
 	// The value to be placed in the template variable
-	Value string `json:"value"`
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Read the value to be placed in the template variable from a ConfigMap
+	// or a Secret instead
+	// +optional
+	ValueFrom *ProjectDevelopmentStreamSpecTemplateValueSource `json:"valueFrom,omitempty"`
 }
 
+// TemplateValuesLabel must be set to "true" on a Secret for
+// ProjectDevelopmentStreams to read template variable values from it. The
+// values end up in the generated resources, so the label makes the owners of
+// the Secret opt into exposing them.
+const TemplateValuesLabel = "projctl.konflux.dev/template-values"
+
+// ProjectDevelopmentStreamSpecTemplateValueSource selects a key of a ConfigMap
+// or a Secret in the namespace of the stream to read a template variable value
+// from. Secrets must have the TemplateValuesLabel set to "true". Values from
+// optional sources that do not exist are treated as not given, so the
+// variable default applies.
+// +kubebuilder:validation:XValidation:rule="has(self.configMapKeyRef) != has(self.secretKeyRef)",message="exactly one of configMapKeyRef and secretKeyRef must be set"
+type ProjectDevelopmentStreamSpecTemplateValueSource struct {
+	// Selects a key of a ConfigMap
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a Secret
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// TemplateKind is the kind of template a ProjectDevelopmentStream refers to
+// +kubebuilder:validation:Enum=ProjectDevelopmentStreamTemplate;ClusterProjectDevelopmentStreamTemplate
+type TemplateKind string
+
+const (
+	// TemplateKindNamespaced refers to a ProjectDevelopmentStreamTemplate in
+	// the namespace of the stream
+	TemplateKindNamespaced TemplateKind = "ProjectDevelopmentStreamTemplate"
+	// TemplateKindCluster refers to a ClusterProjectDevelopmentStreamTemplate
+	TemplateKindCluster TemplateKind = "ClusterProjectDevelopmentStreamTemplate"
+)
+
+// TemplateRevisionLatest refers to the current spec of a template rather than
+// to one of its revisions
+const TemplateRevisionLatest = "latest"
+
 // ProjectDevelopmentStreamSpecTemplateRef defines which optional template is
 // associated with this ProjectDevelopmentStream and how to apply it
-// The template must exist in the same namespace as the stream.
+// A ProjectDevelopmentStreamTemplate must exist in the same namespace as the
+// stream, while a ClusterProjectDevelopmentStreamTemplate can be used by
+// streams in any namespace.
 type ProjectDevelopmentStreamSpecTemplateRef struct {
-	// The name of the ProjectDevelopmentStreamTemplate to use
+	// The name of the template to use
 	Name string `json:"name"`
+	// The kind of the template to use. Defaults to
+	// ProjectDevelopmentStreamTemplate
+	// +optional
+	Kind TemplateKind `json:"kind,omitempty"`
+	// The revision of the template to use, given as the generation of the
+	// template the revision was taken from, followed by the generations of
+	// the templates it extends for templates that extend others (e.g. 3.5.2),
+	// or "latest" to use the current spec of the template. Revisions are only
+	// recorded for the template specs the controller processed, so there may
+	// be no revision for some generations. Defaults to "latest".
+	// +kubebuilder:validation:Pattern=`^(latest|[1-9][0-9]*(\.[1-9][0-9]*)*)$`
+	// +optional
+	Revision string `json:"revision,omitempty"`
 	// Values for template variables
 	Values []ProjectDevelopmentStreamSpecTemplateValue `json:"values,omitempty"`
 }
 
+// PrunePolicy defines what happens to resources that were generated for a
+// ProjectDevelopmentStream but are no longer produced by its template
+// +kubebuilder:validation:Enum=Delete;Orphan
+type PrunePolicy string
+
+const (
+	// PrunePolicyDelete deletes resources the template no longer produces
+	PrunePolicyDelete PrunePolicy = "Delete"
+	// PrunePolicyOrphan leaves resources the template no longer produces in
+	// place and stops tracking them
+	PrunePolicyOrphan PrunePolicy = "Orphan"
+)
+
+// DeletionPolicy defines what happens to the resources that were generated for
+// a ProjectDevelopmentStream when it is deleted
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain-Application
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes all the generated resources
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves all the generated resources in place
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetainApplication leaves generated Application resources
+	// in place and deletes all the other generated resources
+	DeletionPolicyRetainApplication DeletionPolicy = "Retain-Application"
+)
+
+// PatchType defines how a ProjectDevelopmentStreamPatch is applied
+// +kubebuilder:validation:Enum=Merge;JSON
+type PatchType string
+
+const (
+	// PatchTypeMerge applies an RFC 7386 JSON merge patch, i.e. a partial
+	// resource: maps are merged recursively, null values remove fields and
+	// lists are replaced as a whole
+	PatchTypeMerge PatchType = "Merge"
+	// PatchTypeJSON applies a list of RFC 6902 JSON patch operations
+	PatchTypeJSON PatchType = "JSON"
+)
+
+// ProjectDevelopmentStreamPatchTarget selects the generated resource a patch
+// applies to
+type ProjectDevelopmentStreamPatchTarget struct {
+	// Kind of the generated resource
+	Kind string `json:"kind"`
+	// Name of the generated resource, i.e. the name after the template was
+	// rendered
+	Name string `json:"name"`
+}
+
+// ProjectDevelopmentStreamPatch modifies one of the resources generated from
+// the template of a ProjectDevelopmentStream
+type ProjectDevelopmentStreamPatch struct {
+	// The generated resource to patch
+	Target ProjectDevelopmentStreamPatchTarget `json:"target"`
+	// How the patch is applied. Defaults to Merge
+	// +optional
+	Type PatchType `json:"type,omitempty"`
+	// The patch, as a YAML or JSON document. A merge patch is a
+	// partial resource, while a JSON patch is a list of operations. Values
+	// are not processed as templates
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
+}
+
 // ProjectDevelopmentStreamSpec defines the desired state of ProjectDevelopmentStream
 // A development stream typically represents a version or environment branch.
 type ProjectDevelopmentStreamSpec struct {
@@ -52,11 +177,127 @@ type ProjectDevelopmentStreamSpec struct {
 	// An optional template to use for creating resources owned by this
 	// ProjectDevelopmentStream
 	Template *ProjectDevelopmentStreamSpecTemplateRef `json:"template,omitempty"`
+	// What to do with resources that were generated for this stream but are
+	// no longer produced by its template (e.g. because they were removed from
+	// the template or the template reference was removed). Defaults to Delete
+	// +optional
+	PrunePolicy PrunePolicy `json:"prunePolicy,omitempty"`
+	// What to do with the resources that were generated for this stream when
+	// the stream is deleted. Resources left in place have their ownership
+	// reference to the stream removed. Defaults to Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Stop applying the resources of this stream, e.g. so the generated
+	// resources can be modified by hand without the controller reverting the
+	// changes. Resources get applied again once the stream is unpaused. Paused
+	// streams are still cleaned up when deleted
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// Only apply the resources of this stream in dry-run mode, and report the
+	// changes applying them would make in status.dryRun instead of making
+	// them. Useful for previewing the effects of template changes
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Patches to apply, in order, to the resources generated from the
+	// template, e.g. to set a field of a single stream's resource that the
+	// template does not make configurable. Patches can set any field, except
+	// the fields the resource type lists as untouchable, and cannot change
+	// the kind, name or namespace of resources
+	// +optional
+	Patches []ProjectDevelopmentStreamPatch `json:"patches,omitempty"`
+}
+
+// ResourceApplyOutcome describes the result of applying a generated resource
+// +kubebuilder:validation:Enum=Applied;Conflict;Error
+type ResourceApplyOutcome string
+
+const (
+	// ResourceApplied means the resource was applied successfully
+	ResourceApplied ResourceApplyOutcome = "Applied"
+	// ResourceConflict means applying the resource failed due to an update
+	// conflict and will be retried
+	ResourceConflict ResourceApplyOutcome = "Conflict"
+	// ResourceError means applying the resource failed
+	ResourceError ResourceApplyOutcome = "Error"
+)
+
+// ProjectDevelopmentStreamResourceStatus identifies a resource that was
+// generated for a ProjectDevelopmentStream and the result of applying it
+type ProjectDevelopmentStreamResourceStatus struct {
+	// API version of the generated resource
+	APIVersion string `json:"apiVersion"`
+	// Kind of the generated resource
+	Kind string `json:"kind"`
+	// Name of the generated resource
+	Name string `json:"name"`
+	// The outcome of the last attempt to apply the resource
+	// +optional
+	Outcome ResourceApplyOutcome `json:"outcome,omitempty"`
+	// Details about why the last attempt to apply the resource failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// The generation of the ProjectDevelopmentStream for which the resource was
+	// last applied successfully
+	// +optional
+	LastAppliedGeneration int64 `json:"lastAppliedGeneration,omitempty"`
+}
+
+// DryRunAction describes what applying the resources of a
+// ProjectDevelopmentStream would do to a resource
+// +kubebuilder:validation:Enum=Create;Update;Delete;Orphan
+type DryRunAction string
+
+const (
+	// DryRunCreate means the resource would be created
+	DryRunCreate DryRunAction = "Create"
+	// DryRunUpdate means the resource would be modified
+	DryRunUpdate DryRunAction = "Update"
+	// DryRunDelete means the resource would be pruned by deleting it
+	DryRunDelete DryRunAction = "Delete"
+	// DryRunOrphan means the resource would be pruned by orphaning it
+	DryRunOrphan DryRunAction = "Orphan"
+)
+
+// ProjectDevelopmentStreamResourceDiff describes the changes applying the
+// resources of a ProjectDevelopmentStream would make to one of them
+type ProjectDevelopmentStreamResourceDiff struct {
+	// API version of the resource
+	APIVersion string `json:"apiVersion"`
+	// Kind of the resource
+	Kind string `json:"kind"`
+	// Name of the resource
+	Name string `json:"name"`
+	// What applying the resources of the stream would do to the resource. Not
+	// set if applying the resource in dry-run mode failed
+	// +optional
+	Action DryRunAction `json:"action,omitempty"`
+	// The fields that would change, one per line, for resources that would be
+	// updated
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+	// Details about why applying the resource in dry-run mode failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ProjectDevelopmentStreamExcludedResource identifies a resource of the
+// template that is not generated for a ProjectDevelopmentStream because its
+// inclusion condition evaluated to false
+type ProjectDevelopmentStreamExcludedResource struct {
+	// API version of the resource
+	APIVersion string `json:"apiVersion"`
+	// Kind of the resource
+	Kind string `json:"kind"`
+	// Name the resource would have, if it can be determined
+	// +optional
+	Name string `json:"name,omitempty"`
+	// The inclusion condition that evaluated to false
+	Condition string `json:"condition"`
 }
 
 // ProjectDevelopmentStreamStatus defines the observed state of ProjectDevelopmentStream
 // Conditions include:
-// - Ready (reasons: Reconciling, UpdatingOwnerRef, NoTemplate, TemplateFetchFailed, TemplateGenerationFailed, ResourcesApplied, ApplyingResources)
+// - Ready (reasons: Reconciling, UpdatingOwnerRef, NoTemplate, TemplateFetchFailed, ValueSourceFailed, TemplateGenerationFailed, ResourcesApplied, ApplyingResources, ResourceApplyFailed, PruningResources, DeletingResources, Paused, DryRun, RolloutPending)
 type ProjectDevelopmentStreamStatus struct {
 	// Represents the observations of a ProjectDevelopmentStream's current state.
 	// Known .status.conditions.type are: "Ready"
@@ -65,6 +306,32 @@ type ProjectDevelopmentStreamStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// The resources generated from the template for this stream, in the order
+	// they were created, along with the outcome of applying them. Resources
+	// listed here that the template no longer produces get pruned according to
+	// the stream's prunePolicy
+	// +optional
+	Resources []ProjectDevelopmentStreamResourceStatus `json:"resources,omitempty"`
+	// The resources of the template that are not generated for this stream
+	// because of their inclusion conditions. Resources that were generated
+	// before they got excluded get pruned according to the stream's
+	// prunePolicy
+	// +optional
+	ExcludedResources []ProjectDevelopmentStreamExcludedResource `json:"excludedResources,omitempty"`
+	// The resources that applying the resources of the stream would change,
+	// along with the changes. Only reported while the stream is in dry-run
+	// mode
+	// +optional
+	DryRun []ProjectDevelopmentStreamResourceDiff `json:"dryRun,omitempty"`
+	// The generation of the template whose resources were last applied for
+	// this stream
+	// +optional
+	TemplateGeneration int64 `json:"templateGeneration,omitempty"`
+	// The name of the template revision whose resources were last applied for
+	// this stream. Rollouts of template changes, including changes to the
+	// templates it extends, are tracked by this name
+	// +optional
+	TemplateRevision string `json:"templateRevision,omitempty"`
 }
 
 // +kubebuilder:object:root=true
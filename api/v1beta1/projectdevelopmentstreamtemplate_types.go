@@ -22,6 +22,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// ForEachAnnotation can be set on the resources of a template to the name of a
+// list or yaml variable, to generate a copy of the resource for each item of
+// the variable value. The item and its position in the list are available to
+// the resource templates as .item and .itemIndex. The annotation is removed
+// from the generated resources.
+const ForEachAnnotation = "projctl.konflux.dev/for-each"
+
+// IncludeIfAnnotation can be set on the resources of a template to a Go
+// template that decides whether the resource is generated for a given
+// ProjectDevelopmentStream. The template must evaluate to "true" or "false",
+// and can reference the same values as the resource fields. The annotation is
+// removed from the generated resources.
+const IncludeIfAnnotation = "projctl.konflux.dev/include-if"
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 // +kubebuilder:pruning:PreserveUnknownFields
@@ -41,6 +55,107 @@ type ProjectDevelopmentStreamTemplateVariable struct {
 	DefaultValue *string `json:"defaultValue,omitempty"`
 	// Optional description for the variable for display in the UI
 	Description string `json:"description,omitempty"`
+	// The type of the variable values. Defaults to string.
+	// +optional
+	Type VariableType `json:"type,omitempty"`
+	// Optional regular expression the variable values must match. For list
+	// variables, every item must match it.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+	// Optional list of the values allowed for the variable. For list
+	// variables, every item must be one of them.
+	// +optional
+	Enum []string `json:"enum,omitempty"`
+	// If set, a value must be given for the variable by every stream using the
+	// template, even if the variable has a default value
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// VariableType defines which values a template variable accepts. Values are
+// passed to templates as strings, except for yaml variables.
+// +kubebuilder:validation:Enum=string;integer;boolean;semver;list;yaml
+type VariableType string
+
+const (
+	// VariableTypeString accepts any value
+	VariableTypeString VariableType = "string"
+	// VariableTypeInteger accepts decimal integers
+	VariableTypeInteger VariableType = "integer"
+	// VariableTypeBoolean accepts "true" and "false"
+	VariableTypeBoolean VariableType = "boolean"
+	// VariableTypeSemver accepts semantic versions such as 1.2.3, without a
+	// "v" prefix
+	VariableTypeSemver VariableType = "semver"
+	// VariableTypeList accepts comma-separated lists of items. The value is
+	// passed to templates as the list of its items
+	VariableTypeList VariableType = "list"
+	// VariableTypeYAML accepts YAML (or JSON) documents. The value is passed
+	// to templates as the structure it describes, e.g. a list of maps
+	VariableTypeYAML VariableType = "yaml"
+)
+
+// TemplatingMode defines which fields of the template resources are processed
+// as Go templates
+// +kubebuilder:validation:Enum=Allowlist;AllStrings
+type TemplatingMode string
+
+const (
+	// TemplatingModeAllowlist processes the fields the controller knows to be
+	// templatable for each resource type, as well as the fields listed in the
+	// template's templatedFields
+	TemplatingModeAllowlist TemplatingMode = "Allowlist"
+	// TemplatingModeAllStrings processes every string value in the template
+	// resources, except for apiVersion and kind
+	TemplatingModeAllStrings TemplatingMode = "AllStrings"
+)
+
+// TemplatedField identifies a field of the template resources of a given
+// kind to be processed as a Go template
+type TemplatedField struct {
+	// The kind of the resources the field belongs to
+	Kind string `json:"kind"`
+	// The path to the field as a list of keys, e.g. ["spec", "description"].
+	// The special "[]" key stands for all the items of a list, e.g.
+	// ["spec", "params", "[]", "value"] or ["spec", "tags", "[]"]
+	// +kubebuilder:validation:MinItems=1
+	Path []string `json:"path"`
+	// The type the rendered field value is converted to, allowing templates
+	// to set non-string fields. The field must still hold a template string
+	// in the template resources. Defaults to string
+	// +optional
+	Type TemplatedFieldType `json:"type,omitempty"`
+}
+
+// TemplatedFieldType defines the type a templated field value is converted to
+// once rendered
+// +kubebuilder:validation:Enum=string;boolean;integer;number;yaml
+type TemplatedFieldType string
+
+const (
+	// TemplatedFieldTypeString keeps the rendered value as it is
+	TemplatedFieldTypeString TemplatedFieldType = "string"
+	// TemplatedFieldTypeBoolean converts "true" and "false" to a boolean
+	TemplatedFieldTypeBoolean TemplatedFieldType = "boolean"
+	// TemplatedFieldTypeInteger converts decimal integers to an integer
+	TemplatedFieldTypeInteger TemplatedFieldType = "integer"
+	// TemplatedFieldTypeNumber converts decimal numbers to a floating point
+	// number
+	TemplatedFieldTypeNumber TemplatedFieldType = "number"
+	// TemplatedFieldTypeYAML parses the rendered value as a YAML (or JSON)
+	// document and sets the field to the structure it describes, e.g. a map
+	TemplatedFieldTypeYAML TemplatedFieldType = "yaml"
+)
+
+// TemplateReference identifies a template another template extends. A
+// ProjectDevelopmentStreamTemplate must exist in the same namespace as the
+// template extending it.
+type TemplateReference struct {
+	// The name of the template
+	Name string `json:"name"`
+	// The kind of the template. Defaults to ProjectDevelopmentStreamTemplate
+	// +optional
+	Kind TemplateKind `json:"kind,omitempty"`
 }
 
 // ProjectDevelopmentStreamTemplateSpec defines the resources to be generated
@@ -49,14 +164,97 @@ type ProjectDevelopmentStreamTemplateVariable struct {
 type ProjectDevelopmentStreamTemplateSpec struct {
 	// The name of the project this stream template belongs to
 	Project string `json:"project,omitempty"`
+	// Templates to take variables, resources and templated fields from, in
+	// order. Resources of this template replace resources of the extended
+	// templates that have the same kind and name. Cluster templates can only
+	// extend other cluster templates
+	// +optional
+	Extends []TemplateReference `json:"extends,omitempty"`
 	// List of variables to allow customizing the template results. The order
 	// variables in the list is significant as earlier variables can be
 	// referenced by the default values for later variables
 	Variables []ProjectDevelopmentStreamTemplateVariable `json:"variables,omitempty"`
 	// List of resources to be created for version made from this template
 	// certain values for resource properties may include references to
-	// variables using the Go-text/template syntax
+	// variables using the Go-text/template syntax. Resources may be left out
+	// for some streams using the projctl.konflux.dev/include-if annotation,
+	// and repeated for each item of a list using the
+	// projctl.konflux.dev/for-each annotation
 	Resources []UnstructuredObj `json:"resources,omitempty"`
+	// Which fields of the resources are processed as templates. Defaults to
+	// Allowlist
+	// +optional
+	TemplatingMode TemplatingMode `json:"templatingMode,omitempty"`
+	// Fields to process as templates in addition to the fields the controller
+	// knows to be templatable. In the AllStrings templating mode, only the
+	// fields that have a type other than string are used
+	// +optional
+	TemplatedFields []TemplatedField `json:"templatedFields,omitempty"`
+	// How changes to the template are rolled out to the streams that use it.
+	// If not set, all streams are updated at once
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+	// The number of earlier revisions of the template to keep in addition to
+	// the current one. Older revisions get deleted, unless streams are pinned
+	// to them. Defaults to 10
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+}
+
+// RolloutStrategy defines how changes to a template are rolled out to the
+// streams that use it. Streams that already applied an earlier generation of
+// the template only apply a new generation when the strategy allows it, while
+// new streams and streams whose own spec changed apply it right away.
+type RolloutStrategy struct {
+	// Names of streams to update before all the others. For cluster
+	// templates, streams are given as namespace/name
+	// +optional
+	Canaries []string `json:"canaries,omitempty"`
+	// The maximum number of streams that may be updating at the same time,
+	// i.e. that applied the latest template generation but are not Ready yet.
+	// Defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+	// Stop rolling out the latest template generation if any of the streams
+	// that applied it failed. Otherwise, failed streams are ignored
+	// +optional
+	HaltOnFailure bool `json:"haltOnFailure,omitempty"`
+}
+
+// ProjectDevelopmentStreamTemplateStatus defines the observed state of
+// ProjectDevelopmentStreamTemplate
+// Conditions include:
+// - Valid (reasons: TemplateValid, TemplateInvalid)
+type ProjectDevelopmentStreamTemplateStatus struct {
+	// Represents the observations of a ProjectDevelopmentStreamTemplate's
+	// current state.
+	// Known .status.conditions.type are: "Valid"
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// The names of the ProjectDevelopmentStreams that use this template,
+	// sorted by name
+	// +optional
+	DevelopmentStreams []string `json:"developmentStreams,omitempty"`
+	// The templates this template extends, directly or via other templates,
+	// in the order they are merged, with the generations last merged
+	// +optional
+	Bases []TemplateBase `json:"bases,omitempty"`
+}
+
+// TemplateBase identifies a generation of a template another template
+// extends
+type TemplateBase struct {
+	// The kind of the template
+	Kind TemplateKind `json:"kind"`
+	// The name of the template
+	Name string `json:"name"`
+	// The generation of the template
+	Generation int64 `json:"generation"`
 }
 
 // +kubebuilder:object:root=true
@@ -69,7 +267,8 @@ type ProjectDevelopmentStreamTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ProjectDevelopmentStreamTemplateSpec `json:"spec,omitempty"`
+	Spec   ProjectDevelopmentStreamTemplateSpec   `json:"spec,omitempty"`
+	Status ProjectDevelopmentStreamTemplateStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.revision`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ProjectDevelopmentStreamTemplateRevision is an immutable snapshot of the
+// spec of a ProjectDevelopmentStreamTemplate, with the templates it extends
+// merged into it. The controller creates one whenever the template or a
+// template it extends changes, so that ProjectDevelopmentStreams can keep
+// using an older spec of the template. Revisions are named
+// <template>-<generation>, with the generations of the extended templates
+// appended for templates that extend others, e.g. <template>-3.5.2.
+type ProjectDevelopmentStreamTemplateRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The generation of the template this revision was taken from
+	Revision int64 `json:"revision"`
+	// The generations of the templates the template extends this revision
+	// was taken with, in the order they were merged
+	// +optional
+	Bases []TemplateBase `json:"bases,omitempty"`
+	// The template spec at that generation, with the templates it extends
+	// merged into it
+	Spec ProjectDevelopmentStreamTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProjectDevelopmentStreamTemplateRevisionList contains a list of
+// ProjectDevelopmentStreamTemplateRevision
+type ProjectDevelopmentStreamTemplateRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectDevelopmentStreamTemplateRevision `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(
+			GroupVersion,
+			&ProjectDevelopmentStreamTemplateRevision{},
+			&ProjectDevelopmentStreamTemplateRevisionList{},
+		)
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FieldPath is the path to a field in a resource as a list of keys. The
+// special "[]" key stands for all the items of a list, e.g.
+// ["spec", "params", "[]", "value"] or ["spec", "tags", "[]"]
+// +kubebuilder:validation:MinItems=1
+type FieldPath []string
+
+// ResourceTypeAPI identifies the API group, version and kind of a resource
+type ResourceTypeAPI struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// ResourceTypeOwner defines how a generated resource points to the resource
+// that owns it
+type ResourceTypeOwner struct {
+	// The field of the resource that holds the name of its owner
+	NameField FieldPath `json:"nameField"`
+	// The API of the owner resource
+	API ResourceTypeAPI `json:"api"`
+	// Whether the owner is marked as the controller of the resource
+	// +optional
+	Controller bool `json:"controller,omitempty"`
+	// Whether the deletion of the owner is blocked until the resource is
+	// deleted
+	// +optional
+	BlockOwnerDeletion bool `json:"blockOwnerDeletion,omitempty"`
+}
+
+// ResourceTypePolicySpec defines how resources of a given type are generated
+// from ProjectDevelopmentStreamTemplates
+type ResourceTypePolicySpec struct {
+	// The API group/version/kind values the policy applies to. A policy that
+	// applies to a resource type the controller supports out of the box
+	// replaces the built-in definition of that type
+	// +kubebuilder:validation:MinItems=1
+	APIs []ResourceTypeAPI `json:"apis"`
+	// Fields that are processed as templates
+	// +optional
+	TemplatableFields []FieldPath `json:"templatableFields,omitempty"`
+	// Like templatableFields but for fields that contain resource names. The
+	// values generated for such fields must be valid resource names
+	// +optional
+	TemplatableNameFields []FieldPath `json:"templatableNameFields,omitempty"`
+	// Fields that are never set by the controller. They are removed from the
+	// template resources and preserved in existing resources
+	// +optional
+	UntouchableFields []FieldPath `json:"untouchableFields,omitempty"`
+	// Fields that are only set when resources are created
+	// +optional
+	CreateOnlyFields []FieldPath `json:"createOnlyFields,omitempty"`
+	// Fields that are set when resources are created, but on updates are only
+	// set if they still exist in the live resources. Used for fields that
+	// signal one-time processing by another controller
+	// +optional
+	LiveStateConditionalFields []FieldPath `json:"liveStateConditionalFields,omitempty"`
+	// How generated resources point to their owner. If not set, no owner
+	// reference is set on generated resources
+	// +optional
+	Owner *ResourceTypeOwner `json:"owner,omitempty"`
+}
+
+// ResourceTypePolicyStatus defines the observed state of ResourceTypePolicy
+// Conditions include:
+// - Accepted (reasons: PolicyAccepted, PolicyConflict)
+type ResourceTypePolicyStatus struct {
+	// Represents the observations of a ResourceTypePolicy's current state.
+	// Known .status.conditions.type are: "Accepted"
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// ResourceTypePolicy adds a resource type to the types that may be included
+// in ProjectDevelopmentStreamTemplates, or changes how a supported type is
+// handled.
+type ResourceTypePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceTypePolicySpec   `json:"spec,omitempty"`
+	Status ResourceTypePolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ResourceTypePolicyList contains a list of ResourceTypePolicy
+type ResourceTypePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceTypePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &ResourceTypePolicy{}, &ResourceTypePolicyList{})
+		return nil
+	})
+}
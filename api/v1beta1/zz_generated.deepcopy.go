@@ -21,16 +21,159 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProjectDevelopmentStreamTemplate) DeepCopyInto(out *ClusterProjectDevelopmentStreamTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProjectDevelopmentStreamTemplate.
+func (in *ClusterProjectDevelopmentStreamTemplate) DeepCopy() *ClusterProjectDevelopmentStreamTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProjectDevelopmentStreamTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProjectDevelopmentStreamTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProjectDevelopmentStreamTemplateList) DeepCopyInto(out *ClusterProjectDevelopmentStreamTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterProjectDevelopmentStreamTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProjectDevelopmentStreamTemplateList.
+func (in *ClusterProjectDevelopmentStreamTemplateList) DeepCopy() *ClusterProjectDevelopmentStreamTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProjectDevelopmentStreamTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProjectDevelopmentStreamTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProjectDevelopmentStreamTemplateRevision) DeepCopyInto(out *ClusterProjectDevelopmentStreamTemplateRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Bases != nil {
+		in, out := &in.Bases, &out.Bases
+		*out = make([]TemplateBase, len(*in))
+		copy(*out, *in)
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProjectDevelopmentStreamTemplateRevision.
+func (in *ClusterProjectDevelopmentStreamTemplateRevision) DeepCopy() *ClusterProjectDevelopmentStreamTemplateRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProjectDevelopmentStreamTemplateRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProjectDevelopmentStreamTemplateRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProjectDevelopmentStreamTemplateRevisionList) DeepCopyInto(out *ClusterProjectDevelopmentStreamTemplateRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterProjectDevelopmentStreamTemplateRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProjectDevelopmentStreamTemplateRevisionList.
+func (in *ClusterProjectDevelopmentStreamTemplateRevisionList) DeepCopy() *ClusterProjectDevelopmentStreamTemplateRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProjectDevelopmentStreamTemplateRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProjectDevelopmentStreamTemplateRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in FieldPath) DeepCopyInto(out *FieldPath) {
+	{
+		in := &in
+		*out = make(FieldPath, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldPath.
+func (in FieldPath) DeepCopy() FieldPath {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldPath)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Project) DeepCopyInto(out *Project) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Project.
@@ -78,6 +221,21 @@ func (in *ProjectDevelopmentStream) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamExcludedResource) DeepCopyInto(out *ProjectDevelopmentStreamExcludedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamExcludedResource.
+func (in *ProjectDevelopmentStreamExcludedResource) DeepCopy() *ProjectDevelopmentStreamExcludedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamExcludedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectDevelopmentStreamList) DeepCopyInto(out *ProjectDevelopmentStreamList) {
 	*out = *in
@@ -110,6 +268,72 @@ func (in *ProjectDevelopmentStreamList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamPatch) DeepCopyInto(out *ProjectDevelopmentStreamPatch) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamPatch.
+func (in *ProjectDevelopmentStreamPatch) DeepCopy() *ProjectDevelopmentStreamPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamPatchTarget) DeepCopyInto(out *ProjectDevelopmentStreamPatchTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamPatchTarget.
+func (in *ProjectDevelopmentStreamPatchTarget) DeepCopy() *ProjectDevelopmentStreamPatchTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamPatchTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamResourceDiff) DeepCopyInto(out *ProjectDevelopmentStreamResourceDiff) {
+	*out = *in
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamResourceDiff.
+func (in *ProjectDevelopmentStreamResourceDiff) DeepCopy() *ProjectDevelopmentStreamResourceDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamResourceDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamResourceStatus) DeepCopyInto(out *ProjectDevelopmentStreamResourceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamResourceStatus.
+func (in *ProjectDevelopmentStreamResourceStatus) DeepCopy() *ProjectDevelopmentStreamResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectDevelopmentStreamSpec) DeepCopyInto(out *ProjectDevelopmentStreamSpec) {
 	*out = *in
@@ -118,6 +342,11 @@ func (in *ProjectDevelopmentStreamSpec) DeepCopyInto(out *ProjectDevelopmentStre
 		*out = new(ProjectDevelopmentStreamSpecTemplateRef)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]ProjectDevelopmentStreamPatch, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamSpec.
@@ -136,7 +365,9 @@ func (in *ProjectDevelopmentStreamSpecTemplateRef) DeepCopyInto(out *ProjectDeve
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = make([]ProjectDevelopmentStreamSpecTemplateValue, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -153,6 +384,11 @@ func (in *ProjectDevelopmentStreamSpecTemplateRef) DeepCopy() *ProjectDevelopmen
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectDevelopmentStreamSpecTemplateValue) DeepCopyInto(out *ProjectDevelopmentStreamSpecTemplateValue) {
 	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ProjectDevelopmentStreamSpecTemplateValueSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamSpecTemplateValue.
@@ -165,6 +401,31 @@ func (in *ProjectDevelopmentStreamSpecTemplateValue) DeepCopy() *ProjectDevelopm
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamSpecTemplateValueSource) DeepCopyInto(out *ProjectDevelopmentStreamSpecTemplateValueSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamSpecTemplateValueSource.
+func (in *ProjectDevelopmentStreamSpecTemplateValueSource) DeepCopy() *ProjectDevelopmentStreamSpecTemplateValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamSpecTemplateValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectDevelopmentStreamStatus) DeepCopyInto(out *ProjectDevelopmentStreamStatus) {
 	*out = *in
@@ -175,6 +436,23 @@ func (in *ProjectDevelopmentStreamStatus) DeepCopyInto(out *ProjectDevelopmentSt
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ProjectDevelopmentStreamResourceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedResources != nil {
+		in, out := &in.ExcludedResources, &out.ExcludedResources
+		*out = make([]ProjectDevelopmentStreamExcludedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = make([]ProjectDevelopmentStreamResourceDiff, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamStatus.
@@ -187,12 +465,28 @@ func (in *ProjectDevelopmentStreamStatus) DeepCopy() *ProjectDevelopmentStreamSt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamSummary) DeepCopyInto(out *ProjectDevelopmentStreamSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamSummary.
+func (in *ProjectDevelopmentStreamSummary) DeepCopy() *ProjectDevelopmentStreamSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectDevelopmentStreamTemplate) DeepCopyInto(out *ProjectDevelopmentStreamTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamTemplate.
@@ -245,9 +539,77 @@ func (in *ProjectDevelopmentStreamTemplateList) DeepCopyObject() runtime.Object
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamTemplateRevision) DeepCopyInto(out *ProjectDevelopmentStreamTemplateRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Bases != nil {
+		in, out := &in.Bases, &out.Bases
+		*out = make([]TemplateBase, len(*in))
+		copy(*out, *in)
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamTemplateRevision.
+func (in *ProjectDevelopmentStreamTemplateRevision) DeepCopy() *ProjectDevelopmentStreamTemplateRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamTemplateRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProjectDevelopmentStreamTemplateRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamTemplateRevisionList) DeepCopyInto(out *ProjectDevelopmentStreamTemplateRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProjectDevelopmentStreamTemplateRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamTemplateRevisionList.
+func (in *ProjectDevelopmentStreamTemplateRevisionList) DeepCopy() *ProjectDevelopmentStreamTemplateRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamTemplateRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProjectDevelopmentStreamTemplateRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectDevelopmentStreamTemplateSpec) DeepCopyInto(out *ProjectDevelopmentStreamTemplateSpec) {
 	*out = *in
+	if in.Extends != nil {
+		in, out := &in.Extends, &out.Extends
+		*out = make([]TemplateReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.Variables != nil {
 		in, out := &in.Variables, &out.Variables
 		*out = make([]ProjectDevelopmentStreamTemplateVariable, len(*in))
@@ -262,6 +624,23 @@ func (in *ProjectDevelopmentStreamTemplateSpec) DeepCopyInto(out *ProjectDevelop
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TemplatedFields != nil {
+		in, out := &in.TemplatedFields, &out.TemplatedFields
+		*out = make([]TemplatedField, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamTemplateSpec.
@@ -274,6 +653,38 @@ func (in *ProjectDevelopmentStreamTemplateSpec) DeepCopy() *ProjectDevelopmentSt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectDevelopmentStreamTemplateStatus) DeepCopyInto(out *ProjectDevelopmentStreamTemplateStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DevelopmentStreams != nil {
+		in, out := &in.DevelopmentStreams, &out.DevelopmentStreams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bases != nil {
+		in, out := &in.Bases, &out.Bases
+		*out = make([]TemplateBase, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamTemplateStatus.
+func (in *ProjectDevelopmentStreamTemplateStatus) DeepCopy() *ProjectDevelopmentStreamTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectDevelopmentStreamTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectDevelopmentStreamTemplateVariable) DeepCopyInto(out *ProjectDevelopmentStreamTemplateVariable) {
 	*out = *in
@@ -282,6 +693,11 @@ func (in *ProjectDevelopmentStreamTemplateVariable) DeepCopyInto(out *ProjectDev
 		*out = new(string)
 		**out = **in
 	}
+	if in.Enum != nil {
+		in, out := &in.Enum, &out.Enum
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDevelopmentStreamTemplateVariable.
@@ -341,6 +757,305 @@ func (in *ProjectSpec) DeepCopy() *ProjectSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectStatus) DeepCopyInto(out *ProjectStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DevelopmentStreams != nil {
+		in, out := &in.DevelopmentStreams, &out.DevelopmentStreams
+		*out = make([]ProjectDevelopmentStreamSummary, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectStatus.
+func (in *ProjectStatus) DeepCopy() *ProjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTypeAPI) DeepCopyInto(out *ResourceTypeAPI) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTypeAPI.
+func (in *ResourceTypeAPI) DeepCopy() *ResourceTypeAPI {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTypeAPI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTypeOwner) DeepCopyInto(out *ResourceTypeOwner) {
+	*out = *in
+	if in.NameField != nil {
+		in, out := &in.NameField, &out.NameField
+		*out = make(FieldPath, len(*in))
+		copy(*out, *in)
+	}
+	out.API = in.API
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTypeOwner.
+func (in *ResourceTypeOwner) DeepCopy() *ResourceTypeOwner {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTypeOwner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTypePolicy) DeepCopyInto(out *ResourceTypePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTypePolicy.
+func (in *ResourceTypePolicy) DeepCopy() *ResourceTypePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTypePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceTypePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTypePolicyList) DeepCopyInto(out *ResourceTypePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceTypePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTypePolicyList.
+func (in *ResourceTypePolicyList) DeepCopy() *ResourceTypePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTypePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceTypePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTypePolicySpec) DeepCopyInto(out *ResourceTypePolicySpec) {
+	*out = *in
+	if in.APIs != nil {
+		in, out := &in.APIs, &out.APIs
+		*out = make([]ResourceTypeAPI, len(*in))
+		copy(*out, *in)
+	}
+	if in.TemplatableFields != nil {
+		in, out := &in.TemplatableFields, &out.TemplatableFields
+		*out = make([]FieldPath, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(FieldPath, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.TemplatableNameFields != nil {
+		in, out := &in.TemplatableNameFields, &out.TemplatableNameFields
+		*out = make([]FieldPath, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(FieldPath, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.UntouchableFields != nil {
+		in, out := &in.UntouchableFields, &out.UntouchableFields
+		*out = make([]FieldPath, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(FieldPath, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.CreateOnlyFields != nil {
+		in, out := &in.CreateOnlyFields, &out.CreateOnlyFields
+		*out = make([]FieldPath, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(FieldPath, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.LiveStateConditionalFields != nil {
+		in, out := &in.LiveStateConditionalFields, &out.LiveStateConditionalFields
+		*out = make([]FieldPath, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(FieldPath, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(ResourceTypeOwner)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTypePolicySpec.
+func (in *ResourceTypePolicySpec) DeepCopy() *ResourceTypePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTypePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTypePolicyStatus) DeepCopyInto(out *ResourceTypePolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTypePolicyStatus.
+func (in *ResourceTypePolicyStatus) DeepCopy() *ResourceTypePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTypePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.Canaries != nil {
+		in, out := &in.Canaries, &out.Canaries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateBase) DeepCopyInto(out *TemplateBase) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateBase.
+func (in *TemplateBase) DeepCopy() *TemplateBase {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateBase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateReference) DeepCopyInto(out *TemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateReference.
+func (in *TemplateReference) DeepCopy() *TemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplatedField) DeepCopyInto(out *TemplatedField) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplatedField.
+func (in *TemplatedField) DeepCopy() *TemplatedField {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplatedField)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnstructuredObj) DeepCopyInto(out *UnstructuredObj) {
 	*out = *in
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
@@ -29,6 +30,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -37,6 +39,8 @@ import (
 
 	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
 	"github.com/konflux-ci/project-controller/internal/controller"
+	"github.com/konflux-ci/project-controller/internal/template"
+	webhookv1beta1 "github.com/konflux-ci/project-controller/internal/webhook/v1beta1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -58,6 +62,8 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
+	var resourceTypeConfig string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -68,6 +74,11 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the validating admission webhooks will be served. "+
+			"This requires a serving certificate to be provided to the webhook server.")
+	flag.StringVar(&resourceTypeConfig, "resource-type-config", "",
+		"Path to a file defining resource types to support in templates in addition to the built-in ones.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -76,6 +87,17 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	var configuredPolicies []projctlv1beta1.ResourceTypePolicySpec
+	if resourceTypeConfig != "" {
+		var err error
+		configuredPolicies, err = template.LoadResourceTypeConfig(resourceTypeConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to load resource type configuration")
+			os.Exit(1)
+		}
+		template.SetResourceTypePolicies(configuredPolicies)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -125,14 +147,88 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = controller.SetupFieldIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to set up field indexes")
+		os.Exit(1)
+	}
+
+	// The policy controller runs in every replica, while the controllers it
+	// notifies only run in the leader, so notifications are buffered rather
+	// than waited for
+	streamResourceTypeEvents := make(chan event.GenericEvent, 1)
+	templateResourceTypeEvents := make(chan event.GenericEvent, 1)
+	clusterTemplateResourceTypeEvents := make(chan event.GenericEvent, 1)
+	if err = (&controller.ResourceTypePolicyReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		ConfiguredPolicies: configuredPolicies,
+		ResourceTypesChanged: []chan<- event.GenericEvent{
+			streamResourceTypeEvents,
+			templateResourceTypeEvents,
+			clusterTemplateResourceTypeEvents,
+		},
+		Elected: mgr.Elected(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ResourceTypePolicy")
+		os.Exit(1)
+	}
 	if err = (&controller.ProjectDevelopmentStreamReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorder("ProjectDevelopmentStream-controller"),
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		Recorder:             mgr.GetEventRecorder("ProjectDevelopmentStream-controller"),
+		ResourceTypesChanged: streamResourceTypeEvents,
+		APIReader:            mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ProjectDevelopmentStream")
 		os.Exit(1)
 	}
+	if err = (&controller.ProjectReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Project")
+		os.Exit(1)
+	}
+	if err = (&controller.ProjectDevelopmentStreamTemplateReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		Recorder:             mgr.GetEventRecorder("ProjectDevelopmentStreamTemplate-controller"),
+		ResourceTypesChanged: templateResourceTypeEvents,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ProjectDevelopmentStreamTemplate")
+		os.Exit(1)
+	}
+	if err = (&controller.ClusterProjectDevelopmentStreamTemplateReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		Recorder:             mgr.GetEventRecorder("ClusterProjectDevelopmentStreamTemplate-controller"),
+		ResourceTypesChanged: clusterTemplateResourceTypeEvents,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterProjectDevelopmentStreamTemplate")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		if err = webhookv1beta1.SetupProjectDevelopmentStreamTemplateWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ProjectDevelopmentStreamTemplate")
+			os.Exit(1)
+		}
+		if err = webhookv1beta1.SetupClusterProjectDevelopmentStreamTemplateWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterProjectDevelopmentStreamTemplate")
+			os.Exit(1)
+		}
+		if err = webhookv1beta1.SetupProjectDevelopmentStreamWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ProjectDevelopmentStream")
+			os.Exit(1)
+		}
+		if err = webhookv1beta1.SetupProjectDevelopmentStreamTemplateRevisionWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ProjectDevelopmentStreamTemplateRevision")
+			os.Exit(1)
+		}
+		if err = webhookv1beta1.SetupClusterProjectDevelopmentStreamTemplateRevisionWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterProjectDevelopmentStreamTemplateRevision")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
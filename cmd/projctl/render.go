@@ -0,0 +1,284 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+// A flag.Value collecting repeated name=value flags
+type setValues []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue
+
+func (s *setValues) String() string {
+	pairs := make([]string, len(*s))
+	for i, val := range *s {
+		pairs[i] = val.Name + "=" + val.Value
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (s *setValues) Set(pair string) error {
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got '%s'", pair)
+	}
+	*s = append(*s, projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: name, Value: value})
+	return nil
+}
+
+// A flag.Value collecting repeated flags
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// Run the render command and return the exit code
+func runRender(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	templateFile := flags.String("template", "", "Path to a YAML file containing a ProjectDevelopmentStreamTemplate (required)")
+	streamFile := flags.String("stream", "", "Path to a YAML file containing a ProjectDevelopmentStream using the template")
+	projectFile := flags.String("project", "", "Path to a YAML file containing the Project the stream belongs to")
+	namespace := flags.String("namespace", "", "Namespace to render the resources into, overrides the stream namespace")
+	output := flags.String("o", "yaml", "Output format, one of: yaml, json")
+	resourceTypeConfig := flags.String("resource-type-config", "",
+		"Path to a file defining resource types to support in addition to the built-in ones, "+
+			"in the format used by the controller --resource-type-config flag")
+	var baseFiles stringList
+	flags.Var(&baseFiles, "base", "Path to a YAML file containing a template the template extends. "+
+		"May be given multiple times")
+	var values setValues
+	flags.Var(&values, "set", "Set a template variable value as name=value, overrides values given in the stream. "+
+		"May be given multiple times")
+	flags.Usage = func() {
+		_, _ = fmt.Fprint(stderr, "Usage: projctl render --template FILE [--base FILE]... [--stream FILE] [--project FILE] [--set name=value]... [-o yaml|json]\n\n"+
+			"Print the resources the controller would generate for a ProjectDevelopmentStream from a\n"+
+			"ProjectDevelopmentStreamTemplate. No cluster access is needed, so values the stream reads\n"+
+			"from ConfigMaps or Secrets with valueFrom must be given with --set.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *templateFile == "" || flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+	if *output != "yaml" && *output != "json" {
+		_, _ = fmt.Fprintf(stderr, "Unsupported output format: %s\n", *output)
+		return 2
+	}
+	if *resourceTypeConfig != "" {
+		policies, err := template.LoadResourceTypeConfig(*resourceTypeConfig)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 1
+		}
+		template.SetResourceTypePolicies(policies)
+	}
+
+	var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+	// A ClusterProjectDevelopmentStreamTemplate has the same structure as a
+	// ProjectDevelopmentStreamTemplate, so either can be read into pdst
+	if err := readObject(*templateFile, &pdst, "ProjectDevelopmentStreamTemplate", "ClusterProjectDevelopmentStreamTemplate"); err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+	bases := make([]projctlv1beta1.ProjectDevelopmentStreamTemplate, len(baseFiles))
+	for i, baseFile := range baseFiles {
+		if err := readObject(baseFile, &bases[i], "ProjectDevelopmentStreamTemplate", "ClusterProjectDevelopmentStreamTemplate"); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	resolved, err := template.ResolveFromList(&pdst, bases)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Failed to merge extended templates: %v\n", err)
+		return 1
+	}
+	pds := projctlv1beta1.ProjectDevelopmentStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "stream"},
+	}
+	if *streamFile != "" {
+		if err := readObject(*streamFile, &pds, "ProjectDevelopmentStream"); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	if *namespace != "" {
+		pds.SetNamespace(*namespace)
+	}
+	var project *projctlv1beta1.Project
+	if *projectFile != "" {
+		project = &projctlv1beta1.Project{}
+		if err := readObject(*projectFile, project, "Project"); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 1
+		}
+		pds.Spec.Project = project.GetName()
+	}
+
+	resources, excluded, err := render(pds, *resolved, project, values)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Failed to generate resources from template: %v\n", err)
+		return 1
+	}
+	for _, res := range excluded {
+		_, _ = fmt.Fprintf(stderr, "Excluded %s '%s': inclusion condition is false: %s\n", res.Kind, res.Name, res.Condition)
+	}
+	if err := printResources(stdout, resources, *output); err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// Generate resources for the given stream from the given template, after
+// applying the given values on top of the stream's template values. Unlike
+// the controller, the stream does not need to refer to the template, and
+// values read from ConfigMaps or Secrets must be overridden by the given ones. The
+// project may be nil. Like the controller does, resources that have no owner
+// get the stream set as their owner. The resources left out by their
+// inclusion conditions are returned as well.
+func render(
+	pds projctlv1beta1.ProjectDevelopmentStream,
+	pdst projctlv1beta1.ProjectDevelopmentStreamTemplate,
+	project *projctlv1beta1.Project,
+	values []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue,
+) ([]*unstructured.Unstructured, []projctlv1beta1.ProjectDevelopmentStreamExcludedResource, error) {
+	templateRef := projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: pdst.GetName()}
+	if pds.Spec.Template != nil {
+		templateRef.Values = slices.Clone(pds.Spec.Template.Values)
+	}
+	for _, value := range values {
+		idx := slices.IndexFunc(templateRef.Values, func(v projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue) bool {
+			return v.Name == value.Name
+		})
+		if idx == -1 {
+			templateRef.Values = append(templateRef.Values, value)
+		} else {
+			templateRef.Values[idx] = value
+		}
+	}
+	// Values read from ConfigMaps and Secrets are resolved by the controller,
+	// but the command has no cluster to read them from
+	for _, value := range templateRef.Values {
+		if value.ValueFrom != nil {
+			return nil, nil, fmt.Errorf(
+				"the value of template variable '%s' is read from a ConfigMap or Secret, "+
+					"which cannot be read without a cluster, give it with --set %s=VALUE",
+				value.Name, value.Name,
+			)
+		}
+	}
+	pds.Spec.Template = &templateRef
+	resources, excluded, err := template.MkResources(pds, pdst, project)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, resource := range resources {
+		if len(resource.GetOwnerReferences()) == 0 {
+			resource.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: projctlv1beta1.GroupVersion.String(),
+				Kind:       "ProjectDevelopmentStream",
+				Name:       pds.GetName(),
+				UID:        pds.GetUID(),
+			}})
+		}
+	}
+	return resources, excluded, nil
+}
+
+// Read a single object of one of the given kinds from a YAML or JSON file
+func readObject(path string, obj any, kinds ...string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // reading user-specified files is the purpose of the tool
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if !slices.Contains(kinds, typeMeta.Kind) || typeMeta.GroupVersionKind().Group != projctlv1beta1.GroupVersion.Group {
+		return fmt.Errorf("expected a %s in %s, found: %s", strings.Join(kinds, " or "), path, typeMeta.GroupVersionKind())
+	}
+	if err := yaml.UnmarshalStrict(data, obj); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Print the given resources in the given format. YAML output contains a
+// document per resource, JSON output contains a List object.
+func printResources(out io.Writer, resources []*unstructured.Unstructured, format string) error {
+	if format == "json" {
+		list := unstructured.UnstructuredList{Object: map[string]any{"apiVersion": "v1", "kind": "List"}}
+		for _, resource := range resources {
+			list.Items = append(list.Items, *resource)
+		}
+		data, err := list.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return err
+		}
+		indented.WriteString("\n")
+		_, err = indented.WriteTo(out)
+		return err
+	}
+	for i, resource := range resources {
+		data, err := yaml.Marshal(resource.Object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintln(out, "---"); err != nil {
+				return err
+			}
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
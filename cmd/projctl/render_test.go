@@ -0,0 +1,229 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func sample(fname string) string {
+	return filepath.Join("..", "..", "config", "samples", fname)
+}
+
+var _ = Describe("projctl render", func() {
+	var stdout, stderr *bytes.Buffer
+
+	BeforeEach(func() {
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	// Parse the YAML documents printed to stdout
+	parseOutput := func() []unstructured.Unstructured {
+		GinkgoHelper()
+		var objs []unstructured.Unstructured
+		for doc := range strings.SplitSeq(stdout.String(), "---\n") {
+			var obj unstructured.Unstructured
+			Expect(utilyaml.UnmarshalStrict([]byte(doc), &obj.Object)).To(Succeed())
+			objs = append(objs, obj)
+		}
+		return objs
+	}
+
+	It("renders the resources of a stream", func() {
+		Expect(run([]string{
+			"render",
+			"--template", sample("projctl_v1beta1_pdst_w_imagerepo.yaml"),
+			"--stream", sample("projctl_v1beta1_pds_w_imagerepo.yaml"),
+			"--namespace", "my-ns",
+		}, stdout, stderr)).To(Equal(0), stderr.String())
+
+		objs := parseOutput()
+		Expect(objs).To(HaveLen(3))
+		Expect(objs[0].GetKind()).To(Equal("Application"))
+		Expect(objs[0].GetName()).To(Equal("cool-app-2-2-0"))
+		Expect(objs[0].GetNamespace()).To(Equal("my-ns"))
+		Expect(objs[0].GetOwnerReferences()).To(ConsistOf(And(
+			HaveField("Kind", "ProjectDevelopmentStream"),
+			HaveField("Name", "pds-sample-w-imagerepo"),
+		)))
+		Expect(objs[1].GetKind()).To(Equal("Component"))
+		Expect(objs[2].GetKind()).To(Equal("ImageRepository"))
+		Expect(objs[2].GetOwnerReferences()).To(ConsistOf(HaveField("Name", "cool-comp1-2-2-0")))
+	})
+
+	It("lets --set override stream values", func() {
+		Expect(run([]string{
+			"render",
+			"--template", sample("projctl_v1beta1_pdst_w_imagerepo.yaml"),
+			"--stream", sample("projctl_v1beta1_pds_w_imagerepo.yaml"),
+			"--set", "version=3.0.0",
+		}, stdout, stderr)).To(Equal(0), stderr.String())
+
+		Expect(parseOutput()[0].GetName()).To(Equal("cool-app-3-0-0"))
+	})
+
+	It("renders a template without a stream", func() {
+		Expect(run([]string{
+			"render",
+			"--template", sample("projctl_v1beta1_pdst_w_app.yaml"),
+			"--set", "version=1.0.0",
+			"-o", "json",
+		}, stdout, stderr)).To(Equal(0), stderr.String())
+
+		var list unstructured.UnstructuredList
+		Expect(list.UnmarshalJSON(stdout.Bytes())).To(Succeed())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].GetName()).To(Equal("cool-app-1-0-0"))
+	})
+
+	It("makes the given project available to the template", func() {
+		dir := GinkgoT().TempDir()
+		tmpl := filepath.Join(dir, "template.yaml")
+		Expect(os.WriteFile(tmpl, []byte(`apiVersion: projctl.konflux.dev/v1beta1
+kind: ProjectDevelopmentStreamTemplate
+metadata:
+  name: my-template
+spec:
+  resources:
+  - apiVersion: appstudio.redhat.com/v1alpha1
+    kind: Application
+    metadata:
+      name: "{{.project.name}}-app"
+    spec:
+      displayName: "{{.project.displayName}}"
+`), 0o600)).To(Succeed())
+		Expect(run([]string{
+			"render",
+			"--template", tmpl,
+			"--project", sample("projctl_v1beta1_project.yaml"),
+		}, stdout, stderr)).To(Equal(0), stderr.String())
+
+		objs := parseOutput()
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetName()).To(Equal("project-sample-app"))
+		Expect(objs[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue(
+			"displayName", "API demonstration sample project",
+		)))
+	})
+
+	It("merges the given base templates into the template", func() {
+		dir := GinkgoT().TempDir()
+		tmpl := filepath.Join(dir, "template.yaml")
+		Expect(os.WriteFile(tmpl, []byte(`apiVersion: projctl.konflux.dev/v1beta1
+kind: ProjectDevelopmentStreamTemplate
+metadata:
+  name: my-template
+spec:
+  extends:
+  - name: pdst-sample-w-imagerepo
+  resources:
+  - apiVersion: appstudio.redhat.com/v1alpha1
+    kind: Application
+    metadata:
+      name: "cool-app-{{.versionName}}"
+    spec:
+      displayName: "Extended App {{.version}}"
+`), 0o600)).To(Succeed())
+		Expect(run([]string{
+			"render",
+			"--template", tmpl,
+			"--base", sample("projctl_v1beta1_pdst_w_imagerepo.yaml"),
+			"--set", "version=1.0.0",
+		}, stdout, stderr)).To(Equal(0), stderr.String())
+
+		objs := parseOutput()
+		Expect(objs).To(HaveLen(3))
+		Expect(objs[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "Extended App 1.0.0")))
+
+		Expect(run([]string{
+			"render",
+			"--template", tmpl,
+			"--set", "version=1.0.0",
+		}, stdout, stderr)).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("Failed to merge extended templates"))
+	})
+
+	It("reports resource generation failures", func() {
+		Expect(run([]string{
+			"render",
+			"--template", sample("projctl_v1beta1_pdst_w_app.yaml"),
+		}, stdout, stderr)).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring(
+			"template variable 'version' is missing a value and default not defined",
+		))
+	})
+
+	It("requires values read from ConfigMaps or Secrets to be given with --set", func() {
+		dir := GinkgoT().TempDir()
+		stream := filepath.Join(dir, "stream.yaml")
+		Expect(os.WriteFile(stream, []byte(`apiVersion: projctl.konflux.dev/v1beta1
+kind: ProjectDevelopmentStream
+metadata:
+  name: my-stream
+spec:
+  template:
+    name: pdst-sample-w-app
+    values:
+    - name: version
+      valueFrom:
+        configMapKeyRef:
+          name: versions
+          key: version
+`), 0o600)).To(Succeed())
+		args := []string{
+			"render",
+			"--template", sample("projctl_v1beta1_pdst_w_app.yaml"),
+			"--stream", stream,
+		}
+		Expect(run(args, stdout, stderr)).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("give it with --set version=VALUE"))
+
+		stderr.Reset()
+		Expect(run(append(args, "--set", "version=1.0.0"), stdout, stderr)).To(Equal(0), stderr.String())
+		Expect(parseOutput()[0].GetName()).To(Equal("cool-app-1-0-0"))
+	})
+
+	It("reports files containing unexpected objects", func() {
+		Expect(run([]string{
+			"render",
+			"--template", sample("projctl_v1beta1_pds_w_imagerepo.yaml"),
+		}, stdout, stderr)).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("expected a ProjectDevelopmentStreamTemplate"))
+	})
+
+	DescribeTable(
+		"rejects bad usage",
+		func(args ...string) {
+			Expect(run(args, stdout, stderr)).To(Equal(2))
+			Expect(stdout.String()).To(BeEmpty())
+		},
+		Entry("no command"),
+		Entry("unknown command", "frobnicate"),
+		Entry("missing template", "render", "--set", "version=1.0.0"),
+		Entry("malformed value", "render", "--template", "t.yaml", "--set", "version"),
+		Entry("unknown output format", "render", "--template", "t.yaml", "-o", "xml"),
+	)
+})
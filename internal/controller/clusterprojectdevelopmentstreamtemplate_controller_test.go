@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/pkg/testhelpers"
+)
+
+var _ = Describe("ClusterProjectDevelopmentStreamTemplate Controller", func() {
+	ctx := context.Background()
+
+	var testNs string
+
+	BeforeEach(func() {
+		testNs = setupTestNamespace(ctx, k8sClient)
+
+		cpdst := &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{}
+		testhelpers.ResourceFromFile(
+			filepath.Join("..", "..", "config", "samples", "projctl_v1beta1_clusterprojectdevelopmentstreamtemplate.yaml"),
+			cpdst,
+		)
+		Expect(k8sClient.Create(ctx, cpdst)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, ctx, cpdst)
+
+		for _, resFile := range []string{
+			"projctl_v1beta1_project.yaml",
+			"projctl_v1beta1_pds_w_cluster_template.yaml",
+		} {
+			applySampleFile(ctx, k8sClient, resFile, testNs)
+		}
+	})
+
+	It("generates resources for streams in any namespace", func() {
+		pdsReconciler := &ProjectDevelopmentStreamReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStream-controller-tests"),
+		}
+		pdsNsN := types.NamespacedName{Namespace: testNs, Name: "pds-sample-w-cluster-template"}
+		for range 2 {
+			_, err := pdsReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdsNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		checkExpectedFile(ctx, k8sClient, "projctl_v1beta1_pds_w_cluster_template_exp_results.yaml", testNs)
+
+		By("Listing the streams using the template in its status")
+		cpdstReconciler := &ClusterProjectDevelopmentStreamTemplateReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ClusterProjectDevelopmentStreamTemplate-controller-tests"),
+		}
+		cpdstNsN := types.NamespacedName{Name: "cluster-pdst-sample"}
+		_, err := cpdstReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cpdstNsN})
+		Expect(err).NotTo(HaveOccurred())
+		cpdst := &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{}
+		Expect(k8sClient.Get(ctx, cpdstNsN, cpdst)).To(Succeed())
+		Expect(cpdst.Status.DevelopmentStreams).To(ContainElement(pdsNsN.String()))
+		Expect(cpdst.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", ConditionTypeValid),
+			HaveField("Status", metav1.ConditionTrue),
+		)))
+	})
+})
+
+var _ = Describe("getStreamClusterTemplateEventHandler", func() {
+	DescribeTable(
+		"maps streams to the cluster templates they use",
+		func(ref *projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef, expected []reconcile.Request) {
+			pds := mkStream("stream1", "my-project", metav1.ConditionTrue)
+			pds.Namespace = "my-ns"
+			pds.Spec.Template = ref
+			q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+			DeferCleanup(q.ShutDown)
+			getStreamClusterTemplateEventHandler().Create(
+				context.Background(), event.TypedCreateEvent[client.Object]{Object: &pds}, q,
+			)
+			var requests []reconcile.Request
+			for q.Len() > 0 {
+				req, _ := q.Get()
+				q.Done(req)
+				requests = append(requests, req)
+			}
+			Expect(requests).To(Equal(expected))
+		},
+		Entry("no template", nil, nil),
+		Entry(
+			"a namespaced template",
+			&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: "my-template"},
+			nil,
+		),
+		Entry(
+			"a cluster template",
+			&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+				Name: "my-template",
+				Kind: projctlv1beta1.TemplateKindCluster,
+			},
+			[]reconcile.Request{{NamespacedName: client.ObjectKey{Name: "my-template"}}},
+		),
+	)
+})
@@ -0,0 +1,215 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+const (
+	// StreamTemplateNameIndex is the name of the field index for looking up
+	// ProjectDevelopmentStreams by the name of the template they use
+	StreamTemplateNameIndex = "spec.template.name"
+	// StreamProjectIndex is the name of the field index for looking up
+	// ProjectDevelopmentStreams by the project they belong to
+	StreamProjectIndex = "spec.project"
+	// StreamResourceIndex is the name of the field index for looking up
+	// ProjectDevelopmentStreams by the resources listed in their inventory
+	StreamResourceIndex = "status.resources"
+	// StreamValueSourceIndex is the name of the field index for looking up
+	// ProjectDevelopmentStreams by the ConfigMaps and Secrets they read
+	// template values from
+	StreamValueSourceIndex = "spec.template.values.valueFrom"
+	// TemplateExtendsIndex is the name of the field index for looking up
+	// ProjectDevelopmentStreamTemplates and
+	// ClusterProjectDevelopmentStreamTemplates by the templates they extend
+	TemplateExtendsIndex = "spec.extends"
+)
+
+// SetupFieldIndexes registers the field indexes the controllers' map functions
+// use with the given indexer. It must be called before the manager is started.
+func SetupFieldIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(
+		ctx, &projctlv1beta1.ProjectDevelopmentStream{}, StreamTemplateNameIndex, streamTemplateName,
+	); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(
+		ctx, &projctlv1beta1.ProjectDevelopmentStream{}, StreamProjectIndex, streamProject,
+	); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(
+		ctx, &projctlv1beta1.ProjectDevelopmentStream{}, StreamResourceIndex, streamResources,
+	); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(
+		ctx, &projctlv1beta1.ProjectDevelopmentStream{}, StreamValueSourceIndex, streamValueSources,
+	); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(
+		ctx, &projctlv1beta1.ProjectDevelopmentStreamTemplate{}, TemplateExtendsIndex, templateExtends,
+	); err != nil {
+		return err
+	}
+	return indexer.IndexField(
+		ctx, &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{}, TemplateExtendsIndex, templateExtends,
+	)
+}
+
+// Index function returning the name of the template the given stream uses
+func streamTemplateName(o client.Object) []string {
+	pds, ok := o.(*projctlv1beta1.ProjectDevelopmentStream)
+	if !ok || pds.Spec.Template == nil || pds.Spec.Template.Name == "" {
+		return nil
+	}
+	return []string{pds.Spec.Template.Name}
+}
+
+// Index function returning the name of the project the given stream belongs to
+func streamProject(o client.Object) []string {
+	pds, ok := o.(*projctlv1beta1.ProjectDevelopmentStream)
+	if !ok || pds.Spec.Project == "" {
+		return nil
+	}
+	return []string{pds.Spec.Project}
+}
+
+// Index function returning the keys of the resources listed in the inventory
+// of the given stream
+func streamResources(o client.Object) []string {
+	pds, ok := o.(*projctlv1beta1.ProjectDevelopmentStream)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(pds.Status.Resources))
+	for _, res := range pds.Status.Resources {
+		gv, err := schema.ParseGroupVersion(res.APIVersion)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, resourceIndexKey(gv.WithKind(res.Kind).GroupKind(), res.Name))
+	}
+	return keys
+}
+
+// Returns the StreamResourceIndex key for a resource. Like inventory records,
+// keys ignore the API version.
+func resourceIndexKey(gk schema.GroupKind, name string) string {
+	return gk.String() + "/" + name
+}
+
+// Index function returning the keys of the ConfigMaps and Secrets the given
+// stream reads template values from
+func streamValueSources(o client.Object) []string {
+	pds, ok := o.(*projctlv1beta1.ProjectDevelopmentStream)
+	if !ok || pds.Spec.Template == nil {
+		return nil
+	}
+	var keys []string
+	for _, val := range pds.Spec.Template.Values {
+		switch {
+		case val.ValueFrom == nil:
+		case val.ValueFrom.ConfigMapKeyRef != nil:
+			keys = append(keys, valueSourceIndexKey("ConfigMap", val.ValueFrom.ConfigMapKeyRef.Name))
+		case val.ValueFrom.SecretKeyRef != nil:
+			keys = append(keys, valueSourceIndexKey("Secret", val.ValueFrom.SecretKeyRef.Name))
+		}
+	}
+	return keys
+}
+
+// Returns the StreamValueSourceIndex key for a ConfigMap or a Secret
+func valueSourceIndexKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// Index function returning the keys of the templates the given namespaced or
+// cluster template extends
+func templateExtends(o client.Object) []string {
+	var spec *projctlv1beta1.ProjectDevelopmentStreamTemplateSpec
+	switch t := o.(type) {
+	case *projctlv1beta1.ProjectDevelopmentStreamTemplate:
+		spec = &t.Spec
+	case *projctlv1beta1.ClusterProjectDevelopmentStreamTemplate:
+		spec = &t.Spec
+	default:
+		return nil
+	}
+	keys := make([]string, 0, len(spec.Extends))
+	for _, ref := range spec.Extends {
+		keys = append(keys, extendsIndexKey(template.BaseKind(ref), ref.Name))
+	}
+	return keys
+}
+
+// Returns the TemplateExtendsIndex key for a template
+func extendsIndexKey(kind projctlv1beta1.TemplateKind, name string) string {
+	return string(kind) + "/" + name
+}
+
+// Returns the templates that extend the given template, directly or via other
+// templates. Templates are looked up via the TemplateExtendsIndex field index.
+func extendingTemplates(ctx context.Context, c client.Reader, base template.ID) ([]template.ID, error) {
+	seen := map[template.ID]bool{base: true}
+	queue := []template.ID{base}
+	var ret []template.ID
+	add := func(key template.ID) {
+		if !seen[key] {
+			seen[key] = true
+			queue = append(queue, key)
+			ret = append(ret, key)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		match := client.MatchingFields{TemplateExtendsIndex: extendsIndexKey(current.Kind, current.Name)}
+		opts := []client.ListOption{match}
+		if current.Kind == projctlv1beta1.TemplateKindCluster {
+			// Cluster templates can be extended by cluster templates as well
+			// as by namespaced templates in any namespace
+			var cpdstList projctlv1beta1.ClusterProjectDevelopmentStreamTemplateList
+			if err := c.List(ctx, &cpdstList, match); err != nil {
+				return nil, err
+			}
+			for _, cpdst := range cpdstList.Items {
+				add(template.ID{Kind: projctlv1beta1.TemplateKindCluster, Name: cpdst.GetName()})
+			}
+		} else {
+			// Namespaced templates can only be extended by templates in their
+			// own namespace
+			opts = append(opts, client.InNamespace(current.Namespace))
+		}
+		var pdstList projctlv1beta1.ProjectDevelopmentStreamTemplateList
+		if err := c.List(ctx, &pdstList, opts...); err != nil {
+			return nil, err
+		}
+		for _, pdst := range pdstList.Items {
+			add(template.ID{Kind: projctlv1beta1.TemplateKindNamespaced, Namespace: pdst.GetNamespace(), Name: pdst.GetName()})
+		}
+	}
+	return ret, nil
+}
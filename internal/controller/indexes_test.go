@@ -0,0 +1,203 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("Stream lookup event handlers", func() {
+	var reconciler *ProjectDevelopmentStreamReconciler
+
+	mkStreamIn := func(ns, name, project string, ref *projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef) client.Object {
+		pds := mkStream(name, project, "")
+		pds.Namespace = ns
+		pds.Spec.Template = ref
+		return &pds
+	}
+
+	BeforeEach(func() {
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithIndex(&projctlv1beta1.ProjectDevelopmentStream{}, StreamTemplateNameIndex, streamTemplateName).
+			WithIndex(&projctlv1beta1.ProjectDevelopmentStream{}, StreamProjectIndex, streamProject).
+			WithIndex(&projctlv1beta1.ProjectDevelopmentStream{}, StreamResourceIndex, streamResources).
+			WithIndex(&projctlv1beta1.ProjectDevelopmentStream{}, StreamValueSourceIndex, streamValueSources).
+			WithIndex(&projctlv1beta1.ProjectDevelopmentStreamTemplate{}, TemplateExtendsIndex, templateExtends).
+			WithIndex(&projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{}, TemplateExtendsIndex, templateExtends).
+			WithObjects(
+				mkStreamIn("ns1", "uses-template", "project1",
+					&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: "template1"}),
+				mkStreamIn("ns1", "uses-other-template", "project1",
+					&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: "template2"}),
+				mkStreamIn("ns1", "uses-cluster-template", "project2",
+					&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+						Name: "template1", Kind: projctlv1beta1.TemplateKindCluster,
+					}),
+				mkStreamIn("ns2", "uses-template", "project1",
+					&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: "template1"}),
+				mkStreamIn("ns2", "uses-cluster-template", "project1",
+					&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+						Name: "template1", Kind: projctlv1beta1.TemplateKindCluster,
+					}),
+				mkStreamIn("ns1", "no-template", "project2", nil),
+				mkStreamIn("ns1", "uses-settings", "project3",
+					&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+						Name: "template3",
+						Values: []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{{
+							Name: "org",
+							ValueFrom: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource{
+								ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+									Key:                  "org",
+								},
+							},
+						}},
+					}),
+			).
+			WithStatusSubresource(&projctlv1beta1.ProjectDevelopmentStream{}).
+			Build()
+		reconciler = &ProjectDevelopmentStreamReconciler{Client: c, Scheme: scheme.Scheme}
+
+		pds := &projctlv1beta1.ProjectDevelopmentStream{}
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "ns1", Name: "uses-template"}, pds)).To(Succeed())
+		pds.Status.Resources = []projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Application", Name: "my-app"},
+		}
+		Expect(c.Status().Update(context.Background(), pds)).To(Succeed())
+	})
+
+	enqueued := func(h handler.EventHandler, o client.Object) []reconcile.Request {
+		q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		DeferCleanup(q.ShutDown)
+		h.Create(context.Background(), event.TypedCreateEvent[client.Object]{Object: o}, q)
+		var requests []reconcile.Request
+		for q.Len() > 0 {
+			req, _ := q.Get()
+			q.Done(req)
+			requests = append(requests, req)
+		}
+		return requests
+	}
+
+	It("maps a template to the streams in its namespace that use it", func() {
+		pdst := &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "template1"},
+		}
+		Expect(enqueued(getTemplateStreamsEventHandler(reconciler, projctlv1beta1.TemplateKindNamespaced), pdst)).To(
+			ConsistOf(reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: "uses-template"}}),
+		)
+	})
+
+	It("maps a cluster template to the streams in all namespaces that use it", func() {
+		cpdst := &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "template1"},
+		}
+		Expect(enqueued(getTemplateStreamsEventHandler(reconciler, projctlv1beta1.TemplateKindCluster), cpdst)).To(
+			ConsistOf(
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: "uses-cluster-template"}},
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns2", Name: "uses-cluster-template"}},
+			),
+		)
+	})
+
+	It("maps a template to the streams using the templates that extend it", func() {
+		ctx := context.Background()
+		extending := &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "template2"},
+			Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+				Extends: []projctlv1beta1.TemplateReference{{Name: "template2", Kind: projctlv1beta1.TemplateKindCluster}},
+			},
+		}
+		extendingCluster := &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "template1"},
+			Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+				Extends: []projctlv1beta1.TemplateReference{{Name: "template2", Kind: projctlv1beta1.TemplateKindCluster}},
+			},
+		}
+		Expect(reconciler.Create(ctx, extending)).To(Succeed())
+		Expect(reconciler.Create(ctx, extendingCluster)).To(Succeed())
+		Expect(reconciler.Create(ctx, mkStreamIn("ns2", "uses-extending-template", "project1",
+			&projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: "template2"}))).To(Succeed())
+
+		cpdst := &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "template2"},
+		}
+		Expect(enqueued(getTemplateStreamsEventHandler(reconciler, projctlv1beta1.TemplateKindCluster), cpdst)).To(
+			ConsistOf(
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns2", Name: "uses-extending-template"}},
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: "uses-cluster-template"}},
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns2", Name: "uses-cluster-template"}},
+			),
+		)
+		Expect(enqueued(
+			getExtendingTemplatesEventHandler(reconciler.Client, projctlv1beta1.TemplateKindCluster, projctlv1beta1.TemplateKindNamespaced),
+			cpdst,
+		)).To(ConsistOf(reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns2", Name: "template2"}}))
+	})
+
+	It("maps a generated resource to the streams that list it in their inventory", func() {
+		app := &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "my-app"},
+		}
+		gk := schema.GroupKind{Group: "appstudio.redhat.com", Kind: "Application"}
+		Expect(enqueued(getResourceStreamsEventHandler(reconciler, gk), app)).To(
+			ConsistOf(reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: "uses-template"}}),
+		)
+		app.Namespace = "ns2"
+		Expect(enqueued(getResourceStreamsEventHandler(reconciler, gk), app)).To(BeEmpty())
+	})
+
+	It("maps a project to its streams", func() {
+		project := &projctlv1beta1.Project{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "project2"},
+		}
+		Expect(enqueued(getProjectStreamsEventHandler(reconciler), project)).To(
+			ConsistOf(
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: "uses-cluster-template"}},
+				reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: "no-template"}},
+			),
+		)
+	})
+
+	It("maps a ConfigMap or Secret to the streams that read values from it", func() {
+		cm := &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "settings"},
+		}
+		Expect(enqueued(getValueSourceStreamsEventHandler(reconciler, "ConfigMap"), cm)).To(
+			ConsistOf(reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: "uses-settings"}}),
+		)
+		Expect(enqueued(getValueSourceStreamsEventHandler(reconciler, "Secret"), cm)).To(BeEmpty())
+		cm.Namespace = "ns2"
+		Expect(enqueued(getValueSourceStreamsEventHandler(reconciler, "ConfigMap"), cm)).To(BeEmpty())
+	})
+})
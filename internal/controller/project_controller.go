@@ -0,0 +1,208 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// ProjectReconciler reconciles a Project object
+type ProjectReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projects,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projects/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreams,verbs=get;list;watch
+
+// Reconcile updates the status of a Project to reflect the state of the
+// ProjectDevelopmentStreams that belong to it
+func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var project projctlv1beta1.Project
+	if err := r.Get(ctx, req.NamespacedName, &project); err != nil {
+		logger.Error(err, "Unable to fetch Project")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var pdsList projctlv1beta1.ProjectDevelopmentStreamList
+	if err := r.List(
+		ctx, &pdsList,
+		client.InNamespace(project.GetNamespace()),
+		client.MatchingFields{StreamProjectIndex: project.GetName()},
+	); err != nil {
+		logger.Error(err, "Failed listing dev streams of project")
+		return ctrl.Result{}, err
+	}
+
+	status := projectStatusFor(pdsList.Items)
+	if err := r.applyStatus(ctx, &project, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// Calculate the status of a project from the given list of the development
+// streams that belong to it. The Ready condition returned has no timestamp or
+// generation set.
+func projectStatusFor(streams []projctlv1beta1.ProjectDevelopmentStream) projctlv1beta1.ProjectStatus {
+	var status projctlv1beta1.ProjectStatus
+	for _, pds := range streams {
+		summary := projctlv1beta1.ProjectDevelopmentStreamSummary{
+			Name:  pds.Name,
+			Ready: metav1.ConditionUnknown,
+		}
+		if cond := meta.FindStatusCondition(pds.Status.Conditions, ConditionTypeReady); cond != nil {
+			summary.Ready = cond.Status
+			summary.Reason = cond.Reason
+		}
+		switch summary.Ready {
+		case metav1.ConditionTrue:
+			status.ReadyStreams++
+		case metav1.ConditionFalse:
+			status.FailedStreams++
+		}
+		status.DevelopmentStreams = append(status.DevelopmentStreams, summary)
+	}
+	slices.SortFunc(status.DevelopmentStreams, func(a, b projctlv1beta1.ProjectDevelopmentStreamSummary) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	total := len(status.DevelopmentStreams)
+	condition := metav1.Condition{Type: ConditionTypeReady}
+	switch {
+	case total == 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NoDevelopmentStreams"
+		condition.Message = "Project has no development streams"
+	case status.FailedStreams > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "DevelopmentStreamsFailed"
+		condition.Message = fmt.Sprintf("%d of %d development streams failed", status.FailedStreams, total)
+	case int(status.ReadyStreams) < total:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "DevelopmentStreamsNotReady"
+		condition.Message = fmt.Sprintf("%d of %d development streams ready", status.ReadyStreams, total)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "DevelopmentStreamsReady"
+		condition.Message = fmt.Sprintf("All %d development streams ready", total)
+	}
+	status.Conditions = []metav1.Condition{condition}
+	return status
+}
+
+// applyStatus sets the given status on the project using server-side apply
+func (r *ProjectReconciler) applyStatus(ctx context.Context, project *projctlv1beta1.Project, status projctlv1beta1.ProjectStatus) error {
+	logger := log.FromContext(ctx)
+
+	for i := range status.Conditions {
+		condition := &status.Conditions[i]
+		condition.ObservedGeneration = project.Generation
+		condition.LastTransitionTime = metav1.Now()
+		// Preserve LastTransitionTime when status hasn't changed per Kubernetes API conventions.
+		existing := meta.FindStatusCondition(project.Status.Conditions, condition.Type)
+		if existing != nil && existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+
+	gvk, err := r.GroupVersionKindFor(project)
+	if err != nil {
+		logger.Error(err, "Failed to get GVK for Project")
+		return err
+	}
+	applyStatus := &projctlv1beta1.Project{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: project.Namespace,
+			Name:      project.Name,
+		},
+		Status: status,
+	}
+	applyStatus.GetObjectKind().SetGroupVersionKind(gvk)
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(applyStatus)
+	if err != nil {
+		logger.Error(err, "Failed to convert status to unstructured")
+		return err
+	}
+	applyObj := &unstructured.Unstructured{Object: u}
+	if err := r.Status().Apply(ctx, client.ApplyConfigurationFromUnstructured(applyObj), client.FieldOwner(FieldManager)); err != nil {
+		logger.Error(err, "Failed to update Project status")
+		return err
+	}
+	return nil
+}
+
+// Returns a handler for collecting the projects a dev stream belongs to. This
+// includes the project the stream points to as well as any project the stream
+// is owned by, so that when a stream is moved between projects, both projects
+// get updated.
+func getStreamProjectsEventHandler() handler.EventHandler {
+	projectGK := schema.GroupKind{Group: projctlv1beta1.GroupVersion.Group, Kind: "Project"}
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			pds, ok := o.(*projctlv1beta1.ProjectDevelopmentStream)
+			if !ok {
+				return nil
+			}
+			var names []string
+			if pds.Spec.Project != "" {
+				names = append(names, pds.Spec.Project)
+			}
+			for _, ref := range pds.GetOwnerReferences() {
+				gv, err := schema.ParseGroupVersion(ref.APIVersion)
+				if err == nil && gv.WithKind(ref.Kind).GroupKind() == projectGK && !slices.Contains(names, ref.Name) {
+					names = append(names, ref.Name)
+				}
+			}
+			ret := make([]reconcile.Request, len(names))
+			for i, name := range names {
+				ret[i] = reconcile.Request{NamespacedName: client.ObjectKey{Namespace: pds.GetNamespace(), Name: name}}
+			}
+			return ret
+		},
+	)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&projctlv1beta1.Project{}).
+		Watches(
+			&projctlv1beta1.ProjectDevelopmentStream{},
+			getStreamProjectsEventHandler(),
+		).
+		Complete(r)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("Project Controller", func() {
+	ctx := context.Background()
+
+	var testNs string
+
+	BeforeEach(func() {
+		testNs = setupTestNamespace(ctx, k8sClient)
+
+		for _, resFile := range []string{
+			"projctl_v1beta1_project.yaml",
+			"projctl_v1beta1_pds_no_template.yaml",
+			"projctl_v1beta1_pds_template_not_found.yaml",
+		} {
+			applySampleFile(ctx, k8sClient, resFile, testNs)
+		}
+	})
+
+	It("should summarize the state of the project's development streams", func() {
+		pdsReconciler := &ProjectDevelopmentStreamReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStream-controller-tests"),
+		}
+		// Streams are listed via a field index, so the project reconciler
+		// reads from the cache
+		projectReconciler := &ProjectReconciler{
+			Client: saCluster.GetClient(),
+			Scheme: saClient.Scheme(),
+		}
+		projectNsN := types.NamespacedName{Namespace: testNs, Name: "project-sample"}
+
+		By("Reporting the streams before they are reconciled")
+		project := &projctlv1beta1.Project{}
+		Eventually(func(g Gomega) {
+			_, err := projectReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectNsN})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(k8sClient.Get(ctx, projectNsN, project)).To(Succeed())
+			g.Expect(project.Status.DevelopmentStreams).To(HaveLen(2))
+		}).Should(Succeed())
+		Expect(project.Status.Conditions).To(HaveLen(1))
+		Expect(project.Status.Conditions[0].Status).To(Equal(metav1.ConditionUnknown))
+		Expect(project.Status.Conditions[0].Reason).To(Equal("DevelopmentStreamsNotReady"))
+
+		By("Reporting the streams after they are reconciled")
+		for _, pdsName := range []string{"pds-no-template", "pds-template-not-found"} {
+			pdsNsN := types.NamespacedName{Namespace: testNs, Name: pdsName}
+			for range 2 {
+				_, err := pdsReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdsNsN})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+		Eventually(func(g Gomega) {
+			_, err := projectReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectNsN})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(k8sClient.Get(ctx, projectNsN, project)).To(Succeed())
+			g.Expect(project.Status.ReadyStreams + project.Status.FailedStreams).To(Equal(int32(2)))
+		}).Should(Succeed())
+		checkExpectedFile(ctx, k8sClient, "projctl_v1beta1_project_exp_results.yaml", testNs)
+	})
+})
+
+var _ = DescribeTable(
+	"projectStatusFor summarizes the streams of the project",
+	func(streams []projctlv1beta1.ProjectDevelopmentStream, ready, failed int32, status metav1.ConditionStatus, reason string) {
+		projectStatus := projectStatusFor(streams)
+		Expect(projectStatus.ReadyStreams).To(Equal(ready))
+		Expect(projectStatus.FailedStreams).To(Equal(failed))
+		Expect(projectStatus.Conditions).To(HaveLen(1))
+		Expect(projectStatus.Conditions[0].Type).To(Equal(ConditionTypeReady))
+		Expect(projectStatus.Conditions[0].Status).To(Equal(status))
+		Expect(projectStatus.Conditions[0].Reason).To(Equal(reason))
+	},
+	Entry("no streams", nil, int32(0), int32(0), metav1.ConditionTrue, "NoDevelopmentStreams"),
+	Entry(
+		"all streams ready",
+		[]projctlv1beta1.ProjectDevelopmentStream{
+			mkStream("stream1", "my-project", metav1.ConditionTrue),
+			mkStream("stream2", "my-project", metav1.ConditionTrue),
+		},
+		int32(2), int32(0), metav1.ConditionTrue, "DevelopmentStreamsReady",
+	),
+	Entry(
+		"some streams not reconciled yet",
+		[]projctlv1beta1.ProjectDevelopmentStream{
+			mkStream("stream1", "my-project", metav1.ConditionTrue),
+			mkStream("stream2", "my-project", ""),
+		},
+		int32(1), int32(0), metav1.ConditionUnknown, "DevelopmentStreamsNotReady",
+	),
+	Entry(
+		"some streams failed",
+		[]projctlv1beta1.ProjectDevelopmentStream{
+			mkStream("stream1", "my-project", metav1.ConditionTrue),
+			mkStream("stream2", "my-project", metav1.ConditionUnknown),
+			mkStream("stream3", "my-project", metav1.ConditionFalse),
+		},
+		int32(1), int32(1), metav1.ConditionFalse, "DevelopmentStreamsFailed",
+	),
+)
+
+var _ = Describe("getStreamProjectsEventHandler", func() {
+	It("maps a stream to its current and previous projects", func() {
+		pds := mkStream("stream1", "new-project", metav1.ConditionTrue)
+		pds.Namespace = "my-ns"
+		pds.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "projctl.konflux.dev/v1beta1", Kind: "Project", Name: "old-project"},
+			{APIVersion: "projctl.konflux.dev/v1beta1", Kind: "Project", Name: "new-project"},
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "not-a-project"},
+		}
+		q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		DeferCleanup(q.ShutDown)
+		getStreamProjectsEventHandler().Create(
+			context.Background(), event.TypedCreateEvent[client.Object]{Object: &pds}, q,
+		)
+		var requests []reconcile.Request
+		for q.Len() > 0 {
+			req, _ := q.Get()
+			q.Done(req)
+			requests = append(requests, req)
+		}
+		Expect(requests).To(ConsistOf(
+			reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "my-ns", Name: "new-project"}},
+			reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "my-ns", Name: "old-project"}},
+		))
+	})
+})
+
+func mkStream(name, project string, ready metav1.ConditionStatus) projctlv1beta1.ProjectDevelopmentStream {
+	pds := projctlv1beta1.ProjectDevelopmentStream{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       projctlv1beta1.ProjectDevelopmentStreamSpec{Project: project},
+	}
+	if ready != "" {
+		pds.Status.Conditions = []metav1.Condition{{Type: ConditionTypeReady, Status: ready, Reason: "Testing"}}
+	}
+	return pds
+}
@@ -19,21 +19,34 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/diff"
 	"github.com/konflux-ci/project-controller/internal/ownership"
 	"github.com/konflux-ci/project-controller/internal/template"
 	"github.com/konflux-ci/project-controller/pkg/logr/eventr"
@@ -45,6 +58,12 @@ const (
 	ConditionTypeReady = "Ready"
 	// ImageControllerUpdateAnnotation is the annotation that signals image-controller to update Component.spec.containerImage
 	ImageControllerUpdateAnnotation = "image-controller.appstudio.redhat.com/update-component-image"
+	// FieldManager is the server-side apply field manager used for all the
+	// changes the controller makes
+	FieldManager = "projctl.konflux.dev"
+	// StreamFinalizer is the finalizer that keeps a ProjectDevelopmentStream
+	// around until the resources generated for it are removed
+	StreamFinalizer = "projctl.konflux.dev/generated-resources"
 )
 
 // ProjectDevelopmentStreamReconciler reconciles a ProjectDevelopmentStream object
@@ -52,6 +71,25 @@ type ProjectDevelopmentStreamReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder events.EventRecorder
+	// If set, all streams get reconciled when an event is received on this
+	// channel, which happens when the supported resource types change
+	ResourceTypesChanged <-chan event.GenericEvent
+	// If set, used for reading the ConfigMaps and Secrets template values are
+	// taken from, so that their contents do not get cached
+	APIReader client.Reader
+
+	// The types of generated resources watched so far
+	resourceWatches resourceWatches
+}
+
+// What is needed for watching generated resources of types that get
+// supported while the controller runs
+type resourceWatches struct {
+	sync.Mutex
+	controller controller.Controller
+	cache      cache.Cache
+	mapper     meta.RESTMapper
+	watched    map[schema.GroupVersionKind]bool
 }
 
 // +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreams,verbs=get;list;watch;create;update;patch;delete
@@ -60,7 +98,12 @@ type ProjectDevelopmentStreamReconciler struct {
 
 // +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projects,verbs=get;list;watch
 // +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=clusterprojectdevelopmentstreamtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplaterevisions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=clusterprojectdevelopmentstreamtemplaterevisions,verbs=get;list;watch
 
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -89,6 +132,24 @@ func (r *ProjectDevelopmentStreamReconciler) Reconcile(ctx context.Context, req
 	// Update context with the enriched logger so that setReadyCondition can use it
 	ctx = ctrl.LoggerInto(ctx, logger)
 
+	if !pds.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &pds)
+	}
+	if !controllerutil.ContainsFinalizer(&pds, StreamFinalizer) {
+		patch := client.MergeFromWithOptions(pds.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		controllerutil.AddFinalizer(&pds, StreamFinalizer)
+		if err := r.Patch(ctx, &pds, patch); err != nil {
+			logger.Error(err, "Failed to add finalizer to ProjectDevelopmentStream")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if pds.Spec.Paused {
+		logger.V(1).Info("ProjectDevelopmentStream is paused, not applying resources")
+		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionUnknown, "Paused", "Reconciliation is paused, resources are not applied")
+		return ctrl.Result{}, nil
+	}
+
 	// This is arguably better done in an admission hook, but its easier to test
 	// when doing this from the controller
 	if !r.checkProductOwnerRef(pds) {
@@ -108,23 +169,78 @@ func (r *ProjectDevelopmentStreamReconciler) Reconcile(ctx context.Context, req
 	var templateName string
 	if pds.Spec.Template == nil {
 		logger.Info("No template is associated with this ProjectDevelopmentStream")
+		if pds.Spec.DryRun {
+			return r.dryRunResources(ctx, &pds, nil)
+		}
+		// Any resources we generated before the template reference was removed
+		// are now stale
+		pds.Status.Resources = r.pruneResources(ctx, &pds, pds.Status.Resources, pruneOrphans(&pds))
+		if len(pds.Status.Resources) > 0 {
+			_ = r.setReadyCondition(ctx, &pds, metav1.ConditionUnknown, "PruningResources", "Failed to prune some resources, retrying")
+			return ctrl.Result{Requeue: true}, nil
+		}
+		pds.Status.TemplateGeneration = 0
+		pds.Status.TemplateRevision = ""
+		pds.Status.ExcludedResources = nil
 		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionTrue, "NoTemplate", "ProjectDevelopmentStream ready (no template specified)")
 		return ctrl.Result{}, nil
 	}
 	templateName = pds.Spec.Template.Name
-	logger = logger.WithValues("PDS Template", templateName)
+	templateKind := template.RefKind(pds.Spec.Template)
+	logger = logger.WithValues("PDS Template", templateName, "PDS Template kind", templateKind)
 	ctx = ctrl.LoggerInto(ctx, logger)
 
-	var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
-	templateKey := client.ObjectKey{Namespace: pds.GetNamespace(), Name: templateName}
-	if err := r.Get(ctx, templateKey, &pdst); err != nil {
+	pdst, err := template.Get(ctx, r.Client, &pds)
+	if err != nil {
 		logger.Error(err, "Failed to fetch template")
 		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionFalse, "TemplateFetchFailed", fmt.Sprintf("Failed to fetch template: %v", err))
+		if template.IsExtendsError(err) {
+			// Retrying does not help, we get called again once the templates
+			// change
+			return ctrl.Result{}, nil
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	logger.Info(fmt.Sprintf("Applying resources from ProjectDevelopmentStreamTemplate: %s", pdst.Name))
-	resources, err := template.MkResources(pds, pdst)
+	if !pds.Spec.DryRun {
+		blocker, err := r.checkRollout(ctx, &pds, pdst)
+		if err != nil {
+			logger.Error(err, "Failed to check template rollout")
+			return ctrl.Result{}, err
+		}
+		if blocker != "" {
+			logger.V(1).Info("Not applying template revision yet", "revision", templateRevision(pdst), "reason", blocker)
+			// The stream keeps the resources of the previous revision, which
+			// it is not known to be ready with anymore
+			_ = r.setReadyCondition(ctx, &pds, metav1.ConditionUnknown, "RolloutPending", fmt.Sprintf(
+				"Template revision %s is not applied yet: %s", templateRevision(pdst), blocker,
+			))
+			return ctrl.Result{}, nil
+		}
+		// Whatever the outcome of applying the resources is, this is the
+		// template revision the stream is using from now on
+		pds.Status.TemplateGeneration = pdst.Generation
+		pds.Status.TemplateRevision = templateRevision(pdst)
+	}
+
+	values, err := template.ResolveValues(ctx, r.valueReader(), &pds)
+	if err != nil {
+		logger.Error(err, "Failed to read template values")
+		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionFalse, "ValueSourceFailed", fmt.Sprintf("Failed to read template values: %v", err))
+		// The ConfigMaps and Secrets values are read from are watched, so we
+		// get called again once missing ones are created
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	rendered := pds.DeepCopy()
+	rendered.Spec.Template.Values = values
+	project, err := template.GetProject(ctx, r.Client, &pds)
+	if err != nil {
+		logger.Error(err, "Failed to fetch project")
+		return ctrl.Result{}, err
+	}
+
+	logger.Info(fmt.Sprintf("Applying resources from %s: %s", templateKind, pdst.Name))
+	resources, excluded, err := template.MkResources(*rendered, *pdst, project)
 	if err != nil {
 		logger.Error(err, "Failed to generate resources from template")
 		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionFalse, "TemplateGenerationFailed", fmt.Sprintf("Failed to generate resources from template: %v", err))
@@ -132,8 +248,16 @@ func (r *ProjectDevelopmentStreamReconciler) Reconcile(ctx context.Context, req
 		// reconcile loop
 		return ctrl.Result{}, nil
 	}
+	if pds.Spec.DryRun {
+		return r.dryRunResources(ctx, &pds, resources)
+	}
+	for _, res := range excluded {
+		logger.V(1).Info("Resource excluded by its inclusion condition", "kind", res.Kind, "name", res.Name)
+	}
+	pds.Status.ExcludedResources = excluded
 
-	var requeue bool
+	var requeue, failed bool
+	inventory := make([]projctlv1beta1.ProjectDevelopmentStreamResourceStatus, 0, len(resources))
 	for _, resource := range resources {
 		resLogger := logger.WithValues(
 			"apiVersion", resource.GetAPIVersion(),
@@ -146,23 +270,101 @@ func (r *ProjectDevelopmentStreamReconciler) Reconcile(ctx context.Context, req
 			// If the resource does not have an owner set, use the PDS
 			_ = controllerutil.SetOwnerReference(&pds, resource, r.Scheme)
 		}
-		requeue = requeue || r.createOrUpdateResource(ctx, resLogger, resource)
+		resStatus := resourceStatusFor(resource)
+		previous := findResourceStatus(pds.Status.Resources, resStatus)
+		var live *metav1.PartialObjectMetadata
+		var liveErr error
+		if previous != nil {
+			resStatus.LastAppliedGeneration = previous.LastAppliedGeneration
+			live, liveErr = r.getLiveMetadata(ctx, resource)
+		}
+		err := r.createOrUpdateResource(ctx, resLogger, resource, false)
+		switch {
+		case err == nil:
+			resStatus.Outcome = projctlv1beta1.ResourceApplied
+			resStatus.LastAppliedGeneration = pds.Generation
+			if liveErr == nil {
+				reportDrift(resLogger, resource, previous, live)
+			}
+		case apierrors.IsConflict(err):
+			resStatus.Outcome = projctlv1beta1.ResourceConflict
+			resStatus.Message = err.Error()
+			requeue = true
+		default:
+			resStatus.Outcome = projctlv1beta1.ResourceError
+			resStatus.Message = err.Error()
+			requeue = true
+			failed = true
+		}
+		inventory = append(inventory, resStatus)
 	}
 
-	// Set final condition based on whether we need to requeue
-	if requeue {
+	// Prune resources we generated in the past that the template no longer
+	// produces. Resources that fail to be pruned are kept in the inventory so
+	// we try again on the next reconcile.
+	notPruned := r.pruneResources(ctx, &pds, staleResources(pds.Status.Resources, inventory), pruneOrphans(&pds))
+	pds.Status.Resources = append(inventory, notPruned...)
+
+	// Set final condition based on the outcome of applying the resources
+	switch {
+	case failed:
+		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionFalse, "ResourceApplyFailed", "Failed to apply some resources, see status.resources for details")
+	case requeue:
 		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionUnknown, "ApplyingResources", "Resource conflicts detected, retrying")
-	} else {
+	case len(notPruned) > 0:
+		requeue = true
+		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionUnknown, "PruningResources", "Failed to prune some resources, retrying")
+	default:
 		_ = r.setReadyCondition(ctx, &pds, metav1.ConditionTrue, "ResourcesApplied", "All resources applied successfully")
 	}
 
 	return ctrl.Result{Requeue: requeue}, nil
 }
 
-// Create or update the given resource. Returns true if there is an update
-// conflict for the resource and therefore the reconcile action should be
-// re-queued.
-func (r *ProjectDevelopmentStreamReconciler) createOrUpdateResource(ctx context.Context, logger logr.Logger, resource *unstructured.Unstructured) bool {
+// Remove the resources generated for a stream that is being deleted according
+// to its deletion policy, and then remove our finalizer from it so the deletion
+// can complete. Resources that fail to be removed are kept in the inventory
+// and retried.
+func (r *ProjectDevelopmentStreamReconciler) finalize(ctx context.Context, pds *projctlv1beta1.ProjectDevelopmentStream) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(pds, StreamFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if len(pds.Status.Resources) > 0 {
+		logger.Info(fmt.Sprintf(
+			"Removing %d generated resources with deletion policy: %s",
+			len(pds.Status.Resources), pds.Spec.DeletionPolicy,
+		))
+		pds.Status.Resources = r.pruneResources(ctx, pds, pds.Status.Resources, deletionOrphans(pds))
+		if len(pds.Status.Resources) > 0 {
+			_ = r.setReadyCondition(ctx, pds, metav1.ConditionUnknown, "DeletingResources", fmt.Sprintf(
+				"Failed to remove %d generated resources, retrying", len(pds.Status.Resources),
+			))
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+
+	patch := client.MergeFromWithOptions(pds.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(pds, StreamFinalizer)
+	if err := r.Patch(ctx, pds, patch); err != nil {
+		logger.Error(err, "Failed to remove finalizer from ProjectDevelopmentStream")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// Create or update the given resource. Returns the error that occurred while
+// applying the resource, if any. Callers can use apierrors.IsConflict to find
+// whether the error was due to an update conflict that should be retried. In
+// dry-run mode nothing is changed and the resource is set to the state applying
+// it would result in.
+func (r *ProjectDevelopmentStreamReconciler) createOrUpdateResource(
+	ctx context.Context,
+	logger logr.Logger,
+	resource *unstructured.Unstructured,
+	dryRun bool,
+) error {
 	// Only check if resource exists if we need to handle createOnlyFields or liveStateConditionalFields
 	needsExistenceCheck := template.HasCreateOnlyFields(resource) ||
 		len(template.GetLiveStateConditionalFields(resource)) > 0
@@ -173,7 +375,7 @@ func (r *ProjectDevelopmentStreamReconciler) createOrUpdateResource(ctx context.
 		exists, err = r.resourceExists(ctx, resource)
 		if err != nil {
 			logger.Error(err, "Failed to check if resource exists", "name", resource.GetName(), "kind", resource.GetKind())
-			return true
+			return err
 		}
 	}
 
@@ -225,18 +427,353 @@ func (r *ProjectDevelopmentStreamReconciler) createOrUpdateResource(ctx context.
 	}
 
 	// Apply the resource using Server-Side Apply with ForceOwnership.
+	opts := []client.PatchOption{client.FieldOwner(FieldManager), client.ForceOwnership}
+	if dryRun {
+		opts = append(opts, client.DryRunAll)
+	}
 	err := r.Patch(
 		ctx,
 		resource,
 		client.Apply, //nolint:staticcheck // deprecated: will be migrated to new Apply API in future
-		client.FieldOwner("projctl.konflux.dev"),
-		client.ForceOwnership,
+		opts...,
 	)
 	if err != nil {
 		logger.Error(err, fmt.Sprintf("Failed to create or update resource: %s [%s]", resource.GetName(), resource.GetKind()))
-		return apierrors.IsConflict(err)
+		return err
+	}
+	if !dryRun {
+		logger.Info(fmt.Sprintf("Resource updated: %s [%s]", resource.GetName(), resource.GetKind()))
+	}
+	return nil
+}
+
+// Apply the given resources generated for the stream in dry-run mode, and
+// record the changes applying them for real would make, including pruning
+// resources the template no longer produces, in the stream status instead of
+// making them
+func (r *ProjectDevelopmentStreamReconciler) dryRunResources(
+	ctx context.Context,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+	resources []*unstructured.Unstructured,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	var diffs []projctlv1beta1.ProjectDevelopmentStreamResourceDiff
+	current := make([]projctlv1beta1.ProjectDevelopmentStreamResourceStatus, 0, len(resources))
+	for _, resource := range resources {
+		resLogger := logger.WithValues(
+			"apiVersion", resource.GetAPIVersion(),
+			"kind", resource.GetKind(),
+			"name", resource.GetName(),
+		)
+		current = append(current, resourceStatusFor(resource))
+		resDiff, err := r.dryRunResource(ctx, resLogger, pds, resource)
+		if err != nil {
+			resDiff.Message = err.Error()
+		}
+		if resDiff.Action != "" || resDiff.Message != "" {
+			diffs = append(diffs, resDiff)
+		}
+	}
+	orphan := pruneOrphans(pds)
+	for _, res := range staleResources(pds.Status.Resources, current) {
+		resDiff := projctlv1beta1.ProjectDevelopmentStreamResourceDiff{
+			APIVersion: res.APIVersion,
+			Kind:       res.Kind,
+			Name:       res.Name,
+			Action:     projctlv1beta1.DryRunDelete,
+		}
+		if orphan(res) {
+			resDiff.Action = projctlv1beta1.DryRunOrphan
+		}
+		logger.Info(
+			fmt.Sprintf("Dry run: resource would be pruned: %s [%s]", res.Name, res.Kind),
+			eventr.ReasonLogKey, "DryRun",
+		)
+		diffs = append(diffs, resDiff)
+	}
+
+	pds.Status.DryRun = diffs
+	_ = r.setReadyCondition(ctx, pds, metav1.ConditionUnknown, "DryRun", fmt.Sprintf(
+		"Dry run: %d resources would be changed, see status.dryRun for details", len(diffs),
+	))
+	return ctrl.Result{}, nil
+}
+
+// Apply the given resource in dry-run mode and return the changes applying it
+// for real would make. The returned record has no action set if nothing would
+// change.
+func (r *ProjectDevelopmentStreamReconciler) dryRunResource(
+	ctx context.Context,
+	logger logr.Logger,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+	resource *unstructured.Unstructured,
+) (projctlv1beta1.ProjectDevelopmentStreamResourceDiff, error) {
+	resDiff := projctlv1beta1.ProjectDevelopmentStreamResourceDiff{
+		APIVersion: resource.GetAPIVersion(),
+		Kind:       resource.GetKind(),
+		Name:       resource.GetName(),
+	}
+	ownership.AddMissingUIDs(ctx, r.Client, resource)
+	if len(resource.GetOwnerReferences()) <= 0 {
+		_ = controllerutil.SetOwnerReference(pds, resource, r.Scheme)
+	}
+	// Owners that do not exist yet would get created before the resource when
+	// applying for real, but in dry-run mode they never get created
+	resource.SetOwnerReferences(slices.DeleteFunc(resource.GetOwnerReferences(), func(ref metav1.OwnerReference) bool {
+		return ref.UID == ""
+	}))
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(resource.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKeyFromObject(resource), live)
+	exists := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, fmt.Sprintf("Failed to fetch resource for dry run: %s [%s]", resource.GetName(), resource.GetKind()))
+		return resDiff, err
+	}
+	if err := r.createOrUpdateResource(ctx, logger, resource, true); err != nil {
+		return resDiff, err
+	}
+
+	if !exists {
+		resDiff.Action = projctlv1beta1.DryRunCreate
+		logger.Info(
+			fmt.Sprintf("Dry run: resource would be created: %s [%s]", resource.GetName(), resource.GetKind()),
+			eventr.ReasonLogKey, "DryRun",
+		)
+		return resDiff, nil
+	}
+	if changes := diff.Objects(comparableContent(live), comparableContent(resource)); len(changes) > 0 {
+		resDiff.Action = projctlv1beta1.DryRunUpdate
+		resDiff.Changes = changes
+		logger.Info(
+			fmt.Sprintf("Dry run: %d fields would be changed in resource: %s [%s]", len(changes), resource.GetName(), resource.GetKind()),
+			eventr.ReasonLogKey, "DryRun",
+		)
+	}
+	return resDiff, nil
+}
+
+// Returns the content of the given object without its status and the metadata
+// fields that change whenever the object is modified
+func comparableContent(obj *unstructured.Unstructured) map[string]any {
+	content := obj.DeepCopy().Object
+	unstructured.RemoveNestedField(content, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "creationTimestamp", "uid"} {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	return content
+}
+
+// Fetch the metadata of the live copy of the given resource. Returns nil if
+// the resource does not exist.
+func (r *ProjectDevelopmentStreamReconciler) getLiveMetadata(
+	ctx context.Context,
+	resource *unstructured.Unstructured,
+) (*metav1.PartialObjectMetadata, error) {
+	live := &metav1.PartialObjectMetadata{}
+	live.SetGroupVersionKind(resource.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKeyFromObject(resource), live)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+// Report, via the given logger, if applying the given resource corrected drift,
+// meaning the resource was applied successfully before but got deleted or
+// modified by someone other than the controller since. The previous inventory
+// record and live metadata are the ones found before the resource was applied.
+func reportDrift(
+	logger logr.Logger,
+	resource *unstructured.Unstructured,
+	previous *projctlv1beta1.ProjectDevelopmentStreamResourceStatus,
+	live *metav1.PartialObjectMetadata,
+) {
+	if previous == nil || previous.Outcome != projctlv1beta1.ResourceApplied {
+		return
+	}
+	switch {
+	case live == nil:
+		logger.Info(
+			fmt.Sprintf("Resource drift corrected, deleted resource recreated: %s [%s]", resource.GetName(), resource.GetKind()),
+			eventr.ReasonLogKey, "DriftCorrected",
+		)
+	case live.GetResourceVersion() != resource.GetResourceVersion() && lastFieldManager(live) != FieldManager:
+		logger.Info(
+			fmt.Sprintf("Resource drift corrected, modified resource restored: %s [%s]", resource.GetName(), resource.GetKind()),
+			eventr.ReasonLogKey, "DriftCorrected",
+		)
+	}
+}
+
+// Returns the field manager that made the latest change to the given object,
+// not counting changes to subresources such as status. Timestamps only have a
+// one second resolution, so if a change shares its timestamp with one of our
+// own, we assume the other change was made last.
+func lastFieldManager(object metav1.Object) string {
+	var last *metav1.ManagedFieldsEntry
+	for _, entry := range object.GetManagedFields() {
+		if entry.Subresource != "" || entry.Time == nil {
+			continue
+		}
+		if last == nil || last.Time.Before(entry.Time) ||
+			(last.Time.Equal(entry.Time) && last.Manager == FieldManager) {
+			last = &entry
+		}
+	}
+	if last == nil {
+		return ""
+	}
+	return last.Manager
+}
+
+// Returns the inventory record for the given generated resource
+func resourceStatusFor(resource *unstructured.Unstructured) projctlv1beta1.ProjectDevelopmentStreamResourceStatus {
+	return projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+		APIVersion: resource.GetAPIVersion(),
+		Kind:       resource.GetKind(),
+		Name:       resource.GetName(),
+	}
+}
+
+// Check whether two inventory records refer to the same resource. Records
+// for different versions of the same API group refer to the same resource.
+func sameResource(a, b projctlv1beta1.ProjectDevelopmentStreamResourceStatus) bool {
+	aGV, aErr := schema.ParseGroupVersion(a.APIVersion)
+	bGV, bErr := schema.ParseGroupVersion(b.APIVersion)
+	if aErr != nil || bErr != nil {
+		return a.APIVersion == b.APIVersion && a.Kind == b.Kind && a.Name == b.Name
+	}
+	return aGV.Group == bGV.Group && a.Kind == b.Kind && a.Name == b.Name
+}
+
+// Find the inventory record for the given resource, returns nil if not found
+func findResourceStatus(
+	inventory []projctlv1beta1.ProjectDevelopmentStreamResourceStatus,
+	res projctlv1beta1.ProjectDevelopmentStreamResourceStatus,
+) *projctlv1beta1.ProjectDevelopmentStreamResourceStatus {
+	for i := range inventory {
+		if sameResource(inventory[i], res) {
+			return &inventory[i]
+		}
+	}
+	return nil
+}
+
+// Returns the resources found in the previous inventory that are missing
+// from the current one
+func staleResources(previous, current []projctlv1beta1.ProjectDevelopmentStreamResourceStatus) []projctlv1beta1.ProjectDevelopmentStreamResourceStatus {
+	var stale []projctlv1beta1.ProjectDevelopmentStreamResourceStatus
+	for _, res := range previous {
+		if findResourceStatus(current, res) == nil {
+			stale = append(stale, res)
+		}
+	}
+	return stale
+}
+
+// Returns a function telling whether a stale resource should be orphaned
+// rather than deleted according to the prune policy of the given stream
+func pruneOrphans(pds *projctlv1beta1.ProjectDevelopmentStream) func(projctlv1beta1.ProjectDevelopmentStreamResourceStatus) bool {
+	return func(projctlv1beta1.ProjectDevelopmentStreamResourceStatus) bool {
+		return pds.Spec.PrunePolicy == projctlv1beta1.PrunePolicyOrphan
+	}
+}
+
+// Returns a function telling whether a generated resource should be orphaned
+// rather than deleted according to the deletion policy of the given stream
+func deletionOrphans(pds *projctlv1beta1.ProjectDevelopmentStream) func(projctlv1beta1.ProjectDevelopmentStreamResourceStatus) bool {
+	return func(res projctlv1beta1.ProjectDevelopmentStreamResourceStatus) bool {
+		switch pds.Spec.DeletionPolicy {
+		case projctlv1beta1.DeletionPolicyOrphan:
+			return true
+		case projctlv1beta1.DeletionPolicyRetainApplication:
+			gv, err := schema.ParseGroupVersion(res.APIVersion)
+			return err == nil && gv.Group == "appstudio.redhat.com" && res.Kind == "Application"
+		}
+		return false
+	}
+}
+
+// Delete or orphan the given resources that were generated for the stream in
+// the past but are no longer produced by its template, or that need to be
+// removed because the stream is being deleted. The orphan function tells
+// which resources to orphan rather than delete. Resources are pruned in
+// reverse creation order. Only resources that carry our field manager are
+// considered, anything else is left alone and dropped from the inventory.
+// Returns the resources that failed to be pruned.
+func (r *ProjectDevelopmentStreamReconciler) pruneResources(
+	ctx context.Context,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+	stale []projctlv1beta1.ProjectDevelopmentStreamResourceStatus,
+	orphan func(projctlv1beta1.ProjectDevelopmentStreamResourceStatus) bool,
+) []projctlv1beta1.ProjectDevelopmentStreamResourceStatus {
+	logger := log.FromContext(ctx)
+	var notPruned []projctlv1beta1.ProjectDevelopmentStreamResourceStatus
+	for _, res := range slices.Backward(stale) {
+		resLogger := logger.WithValues("apiVersion", res.APIVersion, "kind", res.Kind, "name", res.Name)
+		live := &metav1.PartialObjectMetadata{}
+		live.SetGroupVersionKind(schema.FromAPIVersionAndKind(res.APIVersion, res.Kind))
+		err := r.Get(ctx, client.ObjectKey{Namespace: pds.GetNamespace(), Name: res.Name}, live)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			resLogger.Error(err, fmt.Sprintf("Failed to fetch resource for pruning: %s [%s]", res.Name, res.Kind))
+			notPruned = append(notPruned, res)
+			continue
+		}
+		if !hasFieldManager(live, FieldManager) {
+			resLogger.V(1).Info("Not pruning resource that is not managed by the controller")
+			continue
+		}
+		if orphan(res) {
+			err = r.orphanResource(ctx, pds, live)
+		} else {
+			err = client.IgnoreNotFound(r.Delete(ctx, live))
+		}
+		if err != nil {
+			resLogger.Error(err, fmt.Sprintf("Failed to prune resource: %s [%s]", res.Name, res.Kind))
+			notPruned = append(notPruned, res)
+			continue
+		}
+		resLogger.Info(fmt.Sprintf("Resource pruned: %s [%s]", res.Name, res.Kind))
+	}
+	// We collected the resources in reverse order
+	slices.Reverse(notPruned)
+	return notPruned
+}
+
+// Remove ownership records pointing to the given PDS from the given resource
+// so it does not get garbage-collected along with the PDS
+func (r *ProjectDevelopmentStreamReconciler) orphanResource(
+	ctx context.Context,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+	resource *metav1.PartialObjectMetadata,
+) error {
+	owners := resource.GetOwnerReferences()
+	remaining := slices.DeleteFunc(slices.Clone(owners), func(ref metav1.OwnerReference) bool {
+		return ref.UID == pds.GetUID()
+	})
+	if len(remaining) == len(owners) {
+		return nil
+	}
+	patch := client.MergeFrom(resource.DeepCopy())
+	resource.SetOwnerReferences(remaining)
+	return client.IgnoreNotFound(r.Patch(ctx, resource, patch))
+}
+
+// Check whether the given field manager manages any of the object's fields
+func hasFieldManager(object metav1.Object, manager string) bool {
+	for _, entry := range object.GetManagedFields() {
+		if entry.Manager == manager {
+			return true
+		}
 	}
-	logger.Info(fmt.Sprintf("Resource updated: %s [%s]", resource.GetName(), resource.GetKind()))
 	return false
 }
 
@@ -262,21 +799,134 @@ func (r *ProjectDevelopmentStreamReconciler) setProductOwnerRef(ctx context.Cont
 	return r.Update(ctx, pds)
 }
 
-// Returns a handler for collecting all dev streams that exist on the same namespace as
-// the object passed to the handler
-func getSameNSEventHandler(r *ProjectDevelopmentStreamReconciler) handler.EventHandler {
+// Returns a handler for collecting the dev streams that use a given template
+// of the given kind
+func getTemplateStreamsEventHandler(r *ProjectDevelopmentStreamReconciler, kind projctlv1beta1.TemplateKind) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			return templateStreams(ctx, r, kind, o.GetNamespace(), o.GetName())
+		},
+	)
+}
+
+// Returns requests for the dev streams that use the template of the given kind
+// and name, or any of the templates that extend it. Streams are looked up via
+// the StreamTemplateNameIndex field index, in the given namespace for
+// namespaced templates and across all namespaces for cluster templates.
+func templateStreams(
+	ctx context.Context,
+	r *ProjectDevelopmentStreamReconciler,
+	kind projctlv1beta1.TemplateKind,
+	namespace, name string,
+) []reconcile.Request {
+	lg := log.FromContext(ctx)
+
+	if kind == projctlv1beta1.TemplateKindCluster {
+		namespace = ""
+	}
+	base := template.ID{Kind: kind, Namespace: namespace, Name: name}
+	extending, err := extendingTemplates(ctx, r.Client, base)
+	if err != nil {
+		lg.Error(err, "Failed listing templates extending template")
+	}
+	var ret []reconcile.Request
+	for _, key := range append([]template.ID{base}, extending...) {
+		list := projctlv1beta1.ProjectDevelopmentStreamList{}
+		opts := []client.ListOption{client.MatchingFields{StreamTemplateNameIndex: key.Name}}
+		if key.Kind == projctlv1beta1.TemplateKindNamespaced {
+			opts = append(opts, client.InNamespace(key.Namespace))
+		}
+		if err := r.List(ctx, &list, opts...); err != nil {
+			lg.Error(err, "Failed listing dev streams using template")
+			return nil
+		}
+		for i := range list.Items {
+			if template.UsesTemplate(&list.Items[i], key.Kind, key.Name) {
+				ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+			}
+		}
+	}
+	return ret
+}
+
+// Returns a handler for collecting the dev streams that read template values
+// from a given ConfigMap or Secret, as given by kind. Streams are looked up via
+// the StreamValueSourceIndex field index.
+func getValueSourceStreamsEventHandler(r *ProjectDevelopmentStreamReconciler, kind string) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			lg := log.FromContext(ctx)
+
+			list := projctlv1beta1.ProjectDevelopmentStreamList{}
+			if err := r.List(
+				ctx, &list,
+				client.InNamespace(o.GetNamespace()),
+				client.MatchingFields{StreamValueSourceIndex: valueSourceIndexKey(kind, o.GetName())},
+			); err != nil {
+				lg.Error(err, "Failed listing dev streams reading values from "+kind)
+				return nil
+			}
+			ret := make([]reconcile.Request, 0, len(list.Items))
+			for i := range list.Items {
+				ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+			}
+			return ret
+		},
+	)
+}
+
+// Returns the reader for the ConfigMaps and Secrets template values are taken
+// from
+func (r *ProjectDevelopmentStreamReconciler) valueReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// Returns a handler for collecting the dev streams that belong to a given
+// project. Streams are looked up via the StreamProjectIndex field index.
+func getProjectStreamsEventHandler(r *ProjectDevelopmentStreamReconciler) handler.EventHandler {
 	return handler.EnqueueRequestsFromMapFunc(
 		func(ctx context.Context, o client.Object) []reconcile.Request {
 			lg := log.FromContext(ctx)
 
-			// get all streams from current namespace
 			list := projctlv1beta1.ProjectDevelopmentStreamList{}
-			if err := r.List(ctx, &list, client.InNamespace(o.GetNamespace())); err != nil {
-				lg.Error(err, "Failed listing dev streams in namespace")
+			if err := r.List(
+				ctx, &list,
+				client.InNamespace(o.GetNamespace()),
+				client.MatchingFields{StreamProjectIndex: o.GetName()},
+			); err != nil {
+				lg.Error(err, "Failed listing dev streams of project")
 				return nil
 			}
 			ret := make([]reconcile.Request, len(list.Items))
+			for i := range list.Items {
+				ret[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])}
+			}
+			return ret
+		},
+	)
+}
 
+// Returns a handler for collecting the dev streams that have a generated
+// resource of the given kind listed in their inventory. Streams are looked up
+// via the StreamResourceIndex field index.
+func getResourceStreamsEventHandler(r *ProjectDevelopmentStreamReconciler, gk schema.GroupKind) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			lg := log.FromContext(ctx)
+
+			list := projctlv1beta1.ProjectDevelopmentStreamList{}
+			if err := r.List(
+				ctx, &list,
+				client.InNamespace(o.GetNamespace()),
+				client.MatchingFields{StreamResourceIndex: resourceIndexKey(gk, o.GetName())},
+			); err != nil {
+				lg.Error(err, "Failed listing dev streams of generated resource")
+				return nil
+			}
+			ret := make([]reconcile.Request, len(list.Items))
 			for i := range list.Items {
 				ret[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])}
 			}
@@ -285,6 +935,23 @@ func getSameNSEventHandler(r *ProjectDevelopmentStreamReconciler) handler.EventH
 	)
 }
 
+// Returns a predicate for events about generated resources that may indicate
+// drift. Creations, status updates and changes made by the controller itself
+// are ignored.
+func resourceDriftPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if lastFieldManager(e.ObjectNew) == FieldManager {
+				return false
+			}
+			return predicate.GenerationChangedPredicate{}.Update(e) ||
+				predicate.LabelChangedPredicate{}.Update(e) ||
+				predicate.AnnotationChangedPredicate{}.Update(e)
+		},
+	}
+}
+
 // setReadyCondition sets the Ready condition and updates the status
 func (r *ProjectDevelopmentStreamReconciler) setReadyCondition(ctx context.Context, pds *projctlv1beta1.ProjectDevelopmentStream, status metav1.ConditionStatus, reason, message string) error {
 	logger := log.FromContext(ctx)
@@ -318,9 +985,17 @@ func (r *ProjectDevelopmentStreamReconciler) setReadyCondition(ctx context.Conte
 			Name:      pds.Name,
 		},
 		Status: projctlv1beta1.ProjectDevelopmentStreamStatus{
-			Conditions: []metav1.Condition{condition},
+			Conditions:         []metav1.Condition{condition},
+			Resources:          pds.Status.Resources,
+			ExcludedResources:  pds.Status.ExcludedResources,
+			TemplateGeneration: pds.Status.TemplateGeneration,
+			TemplateRevision:   pds.Status.TemplateRevision,
 		},
 	}
+	// Dry-run results are only kept while the stream is in dry-run mode
+	if pds.Spec.DryRun {
+		applyStatus.Status.DryRun = pds.Status.DryRun
+	}
 	applyStatus.GetObjectKind().SetGroupVersionKind(gvk)
 	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(applyStatus)
 	if err != nil {
@@ -328,26 +1003,130 @@ func (r *ProjectDevelopmentStreamReconciler) setReadyCondition(ctx context.Conte
 		return err
 	}
 	applyObj := &unstructured.Unstructured{Object: u}
-	if err := r.Status().Apply(ctx, client.ApplyConfigurationFromUnstructured(applyObj), client.FieldOwner("projctl.konflux.dev")); err != nil {
+	if err := r.Status().Apply(ctx, client.ApplyConfigurationFromUnstructured(applyObj), client.FieldOwner(FieldManager)); err != nil {
 		logger.Error(err, "Failed to update Ready condition", "reason", reason)
 		return err
 	}
 	return nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// SetupWithManager sets up the controller with the Manager. The field indexes
+// registered by SetupFieldIndexes must be set up on the manager as well.
 func (r *ProjectDevelopmentStreamReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	bldr := ctrl.NewControllerManagedBy(mgr).
 		For(&projctlv1beta1.ProjectDevelopmentStream{}).
+		// Status updates of templates and projects do not affect the streams,
+		// so we only watch for spec changes
 		Watches(
 			&projctlv1beta1.ProjectDevelopmentStreamTemplate{},
-			getSameNSEventHandler(r),
+			getTemplateStreamsEventHandler(r, projctlv1beta1.TemplateKindNamespaced),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{},
+			getTemplateStreamsEventHandler(r, projctlv1beta1.TemplateKindCluster),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		// Streams pinned to a template revision may refer to it before it
+		// gets created
+		Watches(
+			&projctlv1beta1.ProjectDevelopmentStreamTemplateRevision{},
+			getRevisionStreamsEventHandler(r, projctlv1beta1.TemplateKindNamespaced),
+		).
+		Watches(
+			&projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision{},
+			getRevisionStreamsEventHandler(r, projctlv1beta1.TemplateKindCluster),
 		).
 		Watches(
 			&projctlv1beta1.Project{},
-			getSameNSEventHandler(r),
+			getProjectStreamsEventHandler(r),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		).
-		Complete(r)
+		// Streams waiting for a template rollout may proceed when the other
+		// streams using the template get updated. Other stream events are
+		// only handled by the stream itself.
+		Watches(
+			&projctlv1beta1.ProjectDevelopmentStream{},
+			getRolloutStreamsEventHandler(r),
+			builder.WithPredicates(rolloutProgressPredicate()),
+		)
+	// Only the metadata of ConfigMaps and Secrets is watched (and cached),
+	// their contents are read when needed
+	for _, kind := range []string{"ConfigMap", "Secret"} {
+		valueSource := &metav1.PartialObjectMetadata{}
+		valueSource.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind))
+		bldr = bldr.Watches(valueSource, getValueSourceStreamsEventHandler(r, kind), builder.OnlyMetadata)
+	}
+	if r.ResourceTypesChanged != nil {
+		bldr = bldr.WatchesRawSource(source.Channel(
+			r.ResourceTypesChanged,
+			getResourceTypesChangedEventHandler(r),
+		))
+	}
+	ctrlr, err := bldr.Build(r)
+	if err != nil {
+		return err
+	}
+	r.resourceWatches = resourceWatches{
+		controller: ctrlr,
+		cache:      mgr.GetCache(),
+		mapper:     mgr.GetRESTMapper(),
+		watched:    map[schema.GroupVersionKind]bool{},
+	}
+	return r.watchResourceTypes(mgr.GetLogger())
+}
+
+// Watch the metadata of the generated resources of the supported resource
+// types that are not watched yet, so we can repair them if they get modified
+// or deleted. Types the cluster does not know about are skipped, and get
+// watched once the supported resource types change after they are added.
+func (r *ProjectDevelopmentStreamReconciler) watchResourceTypes(logger logr.Logger) error {
+	w := &r.resourceWatches
+	w.Lock()
+	defer w.Unlock()
+	for _, gvk := range template.ResourceAPIs() {
+		if w.watched[gvk] {
+			continue
+		}
+		if _, err := w.mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				logger.Info("Not watching generated resources of unknown type", "gvk", gvk)
+				continue
+			}
+			return err
+		}
+		resource := &metav1.PartialObjectMetadata{}
+		resource.SetGroupVersionKind(gvk)
+		if err := w.controller.Watch(source.Kind(
+			w.cache,
+			client.Object(resource),
+			getResourceStreamsEventHandler(r, gvk.GroupKind()),
+			resourceDriftPredicate(),
+		)); err != nil {
+			return err
+		}
+		w.watched[gvk] = true
+	}
+	return nil
+}
+
+// Returns a handler for the events received when the supported resource
+// types change, which starts watching the generated resources of new types
+// and collects all the streams
+func getResourceTypesChangedEventHandler(r *ProjectDevelopmentStreamReconciler) handler.EventHandler {
+	allStreams := getAllObjectsEventHandler(r.Client, &projctlv1beta1.ProjectDevelopmentStreamList{})
+	return handler.Funcs{
+		GenericFunc: func(
+			ctx context.Context,
+			e event.GenericEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request],
+		) {
+			if err := r.watchResourceTypes(log.FromContext(ctx)); err != nil {
+				log.FromContext(ctx).Error(err, "Failed to watch generated resources of new types")
+			}
+			allStreams.Generic(ctx, e, q)
+		},
+	}
 }
 
 // resourceExists checks if the resource exists in the cluster
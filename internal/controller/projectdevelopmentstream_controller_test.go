@@ -24,7 +24,9 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -32,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -130,6 +133,22 @@ var _ = Describe("ProjectDevelopmentStream Controller", func() {
 			"projctl_v1beta1_pdst_w_existing_comp.yaml",
 			"projctl_v1beta1_pds_w_existing_comp.yaml",
 		),
+		Entry(
+			"Template declaring templated fields",
+			"pds-sample-w-templated-fields",
+			"projctl_v1beta1_pds_w_templated_fields_exp_results.yaml",
+			"projctl_v1beta1_project.yaml",
+			"projctl_v1beta1_pdst_w_templated_fields.yaml",
+			"projctl_v1beta1_pds_w_templated_fields.yaml",
+		),
+		Entry(
+			"Template in AllStrings templating mode",
+			"pds-sample-w-all-strings",
+			"projctl_v1beta1_pds_w_all_strings_exp_results.yaml",
+			"projctl_v1beta1_project.yaml",
+			"projctl_v1beta1_pdst_w_all_strings.yaml",
+			"projctl_v1beta1_pds_w_all_strings.yaml",
+		),
 		// Status: Ready=True, Reason: NoTemplate
 		Entry(
 			"No template specified",
@@ -660,3 +679,792 @@ var _ = Describe("ImageRepository annotation persistence", func() {
 		})
 	})
 })
+
+var _ = Describe("Pruning resources removed from the template", func() {
+	var (
+		ctx        context.Context
+		testNs     string
+		testNsN    types.NamespacedName
+		reconciler *ProjectDevelopmentStreamReconciler
+		imageRepo  *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		testNs = setupTestNamespace(ctx, k8sClient)
+		testNsN = types.NamespacedName{Namespace: testNs, Name: "pds-sample-w-imagerepo"}
+
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_project.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pdst_w_imagerepo.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pds_w_imagerepo.yaml", testNs)
+
+		reconciler = &ProjectDevelopmentStreamReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStream-controller-tests"),
+		}
+
+		imageRepo = &unstructured.Unstructured{}
+		imageRepo.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+		imageRepo.SetKind("ImageRepository")
+		imageRepo.SetNamespace(testNs)
+		imageRepo.SetName("cool-comp1-repo-2-2-0")
+
+		// First reconcile sets the owner reference, second creates resources
+		for range 2 {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(imageRepo), imageRepo)).To(Succeed())
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.Resources).To(ContainElement(And(
+			HaveField("Kind", "ImageRepository"),
+			HaveField("Name", "cool-comp1-repo-2-2-0"),
+			HaveField("Outcome", projctlv1beta1.ResourceApplied),
+		)))
+	})
+
+	removeImageRepoFromTemplate := func() {
+		var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+		pdstKey := types.NamespacedName{Namespace: testNs, Name: "pdst-sample-w-imagerepo"}
+		Expect(k8sClient.Get(ctx, pdstKey, &pdst)).To(Succeed())
+		pdst.Spec.Resources = slices.DeleteFunc(pdst.Spec.Resources, func(res projctlv1beta1.UnstructuredObj) bool {
+			return res.GetKind() == "ImageRepository"
+		})
+		Expect(k8sClient.Update(ctx, &pdst)).To(Succeed())
+	}
+
+	It("deletes resources the template no longer produces", func() {
+		removeImageRepoFromTemplate()
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(imageRepo), imageRepo)
+		Expect(errors.IsNotFound(err)).To(BeTrue(), "ImageRepository should have been pruned")
+
+		pds := getPDS(ctx, k8sClient, testNsN)
+		Expect(pds.Status.Resources).To(HaveLen(2))
+		Expect(pds.Status.Resources).NotTo(ContainElement(HaveField("Kind", "ImageRepository")))
+	})
+
+	It("prunes and reports resources excluded by their inclusion condition", func() {
+		var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+		pdstKey := types.NamespacedName{Namespace: testNs, Name: "pdst-sample-w-imagerepo"}
+		Expect(k8sClient.Get(ctx, pdstKey, &pdst)).To(Succeed())
+		for i := range pdst.Spec.Resources {
+			res := &pdst.Spec.Resources[i]
+			if res.GetKind() == "ImageRepository" {
+				annotations := res.GetAnnotations()
+				annotations[projctlv1beta1.IncludeIfAnnotation] = `{{ne .version "2.2.0"}}`
+				res.SetAnnotations(annotations)
+			}
+		}
+		Expect(k8sClient.Update(ctx, &pdst)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(imageRepo), imageRepo)
+		Expect(errors.IsNotFound(err)).To(BeTrue(), "ImageRepository should have been pruned")
+
+		pds := getPDS(ctx, k8sClient, testNsN)
+		Expect(pds.Status.Resources).NotTo(ContainElement(HaveField("Kind", "ImageRepository")))
+		Expect(pds.Status.ExcludedResources).To(ConsistOf(projctlv1beta1.ProjectDevelopmentStreamExcludedResource{
+			APIVersion: "appstudio.redhat.com/v1alpha1",
+			Kind:       "ImageRepository",
+			Name:       "cool-comp1-repo-2-2-0",
+			Condition:  `{{ne .version "2.2.0"}}`,
+		}))
+		Expect(pds.Status.Conditions).To(ContainElement(HaveField("Reason", "ResourcesApplied")))
+	})
+
+	It("leaves resources in place when the prune policy is Orphan", func() {
+		pds := getPDS(ctx, k8sClient, testNsN)
+		pds.Spec.PrunePolicy = projctlv1beta1.PrunePolicyOrphan
+		Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+		removeImageRepoFromTemplate()
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(imageRepo), imageRepo)).To(Succeed())
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.Resources).NotTo(
+			ContainElement(HaveField("Kind", "ImageRepository")),
+		)
+	})
+
+	It("does not delete resources that are not managed by the controller", func() {
+		// Make it look like someone else took over all the fields we manage
+		imageRepo.SetManagedFields([]metav1.ManagedFieldsEntry{{
+			Manager:    "someone-else",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "appstudio.redhat.com/v1alpha1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{}}`)},
+		}})
+		Expect(k8sClient.Update(ctx, imageRepo)).To(Succeed())
+		removeImageRepoFromTemplate()
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(imageRepo), imageRepo)).To(Succeed())
+	})
+})
+
+var _ = Describe("Repairing drift in generated resources", func() {
+	var (
+		ctx        context.Context
+		testNs     string
+		testNsN    types.NamespacedName
+		reconciler *ProjectDevelopmentStreamReconciler
+		recorder   *events.FakeRecorder
+		app        *unstructured.Unstructured
+	)
+
+	driftEvents := func() []string {
+		var driftEvents []string
+		for {
+			select {
+			case e := <-recorder.Events:
+				if strings.Contains(e, "DriftCorrected") {
+					driftEvents = append(driftEvents, e)
+				}
+			default:
+				return driftEvents
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		testNs = setupTestNamespace(ctx, k8sClient)
+		testNsN = types.NamespacedName{Namespace: testNs, Name: "pds-sample-w-imagerepo"}
+
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_project.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pdst_w_imagerepo.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pds_w_imagerepo.yaml", testNs)
+
+		recorder = events.NewFakeRecorder(100)
+		reconciler = &ProjectDevelopmentStreamReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: recorder,
+		}
+
+		app = &unstructured.Unstructured{}
+		app.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+		app.SetKind("Application")
+		app.SetNamespace(testNs)
+		app.SetName("cool-app-2-2-0")
+
+		// First reconcile sets the owner reference, second creates resources
+		for range 2 {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(app), app)).To(Succeed())
+		Expect(driftEvents()).To(BeEmpty())
+	})
+
+	It("recreates deleted resources", func() {
+		Expect(k8sClient.Delete(ctx, app)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(app), app)).To(Succeed())
+		Expect(driftEvents()).To(ConsistOf(
+			ContainSubstring("deleted resource recreated: cool-app-2-2-0 [Application]"),
+		))
+	})
+
+	It("restores modified resources", func() {
+		Expect(unstructured.SetNestedField(app.Object, "Changed by hand", "spec", "displayName")).To(Succeed())
+		Expect(k8sClient.Update(ctx, app)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(app), app)).To(Succeed())
+		Expect(app.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "Cool App 2.2.0")))
+		Expect(driftEvents()).To(ConsistOf(
+			ContainSubstring("modified resource restored: cool-app-2-2-0 [Application]"),
+		))
+	})
+
+	It("does not restore resources while the stream is paused", func() {
+		setPaused := func(paused bool) {
+			pds := getPDS(ctx, k8sClient, testNsN)
+			pds.Spec.Paused = paused
+			Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		setPaused(true)
+		Expect(unstructured.SetNestedField(app.Object, "Changed by hand", "spec", "displayName")).To(Succeed())
+		Expect(k8sClient.Update(ctx, app)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(app), app)).To(Succeed())
+		Expect(app.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "Changed by hand")))
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.Conditions).To(ContainElement(And(
+			HaveField("Type", ConditionTypeReady),
+			HaveField("Status", metav1.ConditionUnknown),
+			HaveField("Reason", "Paused"),
+		)))
+		Expect(driftEvents()).To(BeEmpty())
+
+		By("Restoring the resources once the stream is unpaused")
+		setPaused(false)
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(app), app)).To(Succeed())
+		Expect(app.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "Cool App 2.2.0")))
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.Conditions).To(ContainElement(And(
+			HaveField("Type", ConditionTypeReady),
+			HaveField("Status", metav1.ConditionTrue),
+		)))
+	})
+
+	It("applies the stream patches to the generated resources", func() {
+		setPatches := func(patches ...projctlv1beta1.ProjectDevelopmentStreamPatch) {
+			pds := getPDS(ctx, k8sClient, testNsN)
+			pds.Spec.Patches = patches
+			Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		comp := &unstructured.Unstructured{}
+		comp.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+		comp.SetKind("Component")
+		revision := func() string {
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testNs, Name: "cool-comp1-2-2-0"}, comp)).To(Succeed())
+			value, _, _ := unstructured.NestedString(comp.Object, "spec", "source", "git", "revision")
+			return value
+		}
+
+		setPatches(projctlv1beta1.ProjectDevelopmentStreamPatch{
+			Target: projctlv1beta1.ProjectDevelopmentStreamPatchTarget{Kind: "Component", Name: "cool-comp1-2-2-0"},
+			Patch:  "spec: {source: {git: {revision: my-branch}}}",
+		})
+		Expect(revision()).To(Equal("my-branch"))
+
+		By("Restoring the template values once the patch is removed")
+		setPatches()
+		Expect(revision()).To(Equal("2.2.0"))
+	})
+
+	It("does not report drift when nothing changed", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(driftEvents()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Dry-run mode", func() {
+	var (
+		ctx        context.Context
+		testNs     string
+		testNsN    types.NamespacedName
+		reconciler *ProjectDevelopmentStreamReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		testNs = setupTestNamespace(ctx, k8sClient)
+		testNsN = types.NamespacedName{Namespace: testNs, Name: "pds-sample-w-imagerepo"}
+
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_project.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pdst_w_imagerepo.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pds_w_imagerepo.yaml", testNs)
+
+		reconciler = &ProjectDevelopmentStreamReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStream-controller-tests"),
+		}
+
+		// First reconcile sets the owner reference, second creates resources
+		for range 2 {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("reports the changes applying the template would make without making them", func() {
+		pds := getPDS(ctx, k8sClient, testNsN)
+		pds.Spec.DryRun = true
+		Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+
+		var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+		pdstKey := types.NamespacedName{Namespace: testNs, Name: "pdst-sample-w-imagerepo"}
+		Expect(k8sClient.Get(ctx, pdstKey, &pdst)).To(Succeed())
+		pdst.Spec.Resources = slices.DeleteFunc(pdst.Spec.Resources, func(res projctlv1beta1.UnstructuredObj) bool {
+			return res.GetKind() == "ImageRepository"
+		})
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object, "Even Cooler App {{.version}}", "spec", "displayName",
+		)).To(Succeed())
+		Expect(k8sClient.Update(ctx, &pdst)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Not changing anything")
+		app := &unstructured.Unstructured{}
+		app.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+		app.SetKind("Application")
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testNs, Name: "cool-app-2-2-0"}, app)).To(Succeed())
+		Expect(app.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "Cool App 2.2.0")))
+		imageRepo := &unstructured.Unstructured{}
+		imageRepo.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+		imageRepo.SetKind("ImageRepository")
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testNs, Name: "cool-comp1-repo-2-2-0"}, imageRepo)).To(Succeed())
+
+		By("Reporting the changes in the status")
+		pds = getPDS(ctx, k8sClient, testNsN)
+		Expect(pds.Status.DryRun).To(ConsistOf(
+			projctlv1beta1.ProjectDevelopmentStreamResourceDiff{
+				APIVersion: "appstudio.redhat.com/v1alpha1",
+				Kind:       "Application",
+				Name:       "cool-app-2-2-0",
+				Action:     projctlv1beta1.DryRunUpdate,
+				Changes:    []string{`~ spec.displayName: "Cool App 2.2.0" -> "Even Cooler App 2.2.0"`},
+			},
+			projctlv1beta1.ProjectDevelopmentStreamResourceDiff{
+				APIVersion: "appstudio.redhat.com/v1alpha1",
+				Kind:       "ImageRepository",
+				Name:       "cool-comp1-repo-2-2-0",
+				Action:     projctlv1beta1.DryRunDelete,
+			},
+		))
+		Expect(pds.Status.Resources).To(ContainElement(HaveField("Kind", "ImageRepository")))
+		Expect(pds.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", ConditionTypeReady),
+			HaveField("Status", metav1.ConditionUnknown),
+			HaveField("Reason", "DryRun"),
+		)))
+
+		By("Applying the changes once dry-run mode is turned off")
+		pds.Spec.DryRun = false
+		Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(app), app)).To(Succeed())
+		Expect(app.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "Even Cooler App 2.2.0")))
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.DryRun).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Deleting a ProjectDevelopmentStream", func() {
+	var (
+		ctx        context.Context
+		testNs     string
+		testNsN    types.NamespacedName
+		reconciler *ProjectDevelopmentStreamReconciler
+	)
+
+	generated := func(kind, name string) *unstructured.Unstructured {
+		res := &unstructured.Unstructured{}
+		res.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+		res.SetKind(kind)
+		res.SetNamespace(testNs)
+		res.SetName(name)
+		return res
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		testNs = setupTestNamespace(ctx, k8sClient)
+		testNsN = types.NamespacedName{Namespace: testNs, Name: "pds-sample-w-imagerepo"}
+
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_project.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pdst_w_imagerepo.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pds_w_imagerepo.yaml", testNs)
+
+		reconciler = &ProjectDevelopmentStreamReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStream-controller-tests"),
+		}
+
+		// First reconcile sets the owner reference, second creates resources
+		for range 2 {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(getPDS(ctx, k8sClient, testNsN).Finalizers).To(ContainElement(StreamFinalizer))
+	})
+
+	deleteStream := func(policy projctlv1beta1.DeletionPolicy) {
+		pds := getPDS(ctx, k8sClient, testNsN)
+		pds.Spec.DeletionPolicy = policy
+		Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, &pds)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, testNsN, &pds)
+		Expect(errors.IsNotFound(err)).To(BeTrue(), "ProjectDevelopmentStream should have been deleted")
+	}
+
+	DescribeTable(
+		"removes the generated resources according to the deletion policy",
+		func(policy projctlv1beta1.DeletionPolicy, keptKinds []string) {
+			deleteStream(policy)
+
+			for _, res := range []*unstructured.Unstructured{
+				generated("Application", "cool-app-2-2-0"),
+				generated("Component", "cool-comp1-2-2-0"),
+				generated("ImageRepository", "cool-comp1-repo-2-2-0"),
+			} {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(res), res)
+				if slices.Contains(keptKinds, res.GetKind()) {
+					Expect(err).NotTo(HaveOccurred(), "%s should have been kept", res.GetKind())
+					Expect(res.GetOwnerReferences()).NotTo(
+						ContainElement(HaveField("Kind", "ProjectDevelopmentStream")),
+					)
+				} else {
+					Expect(errors.IsNotFound(err)).To(BeTrue(), "%s should have been deleted", res.GetKind())
+				}
+			}
+		},
+		Entry("default", projctlv1beta1.DeletionPolicy(""), nil),
+		Entry("Delete", projctlv1beta1.DeletionPolicyDelete, nil),
+		Entry(
+			"Orphan",
+			projctlv1beta1.DeletionPolicyOrphan,
+			[]string{"Application", "Component", "ImageRepository"},
+		),
+		Entry(
+			"Retain-Application",
+			projctlv1beta1.DeletionPolicyRetainApplication,
+			[]string{"Application"},
+		),
+	)
+})
+
+var _ = DescribeTable(
+	"lastFieldManager finds the manager of the latest change",
+	func(entries []metav1.ManagedFieldsEntry, expected string) {
+		obj := metav1.ObjectMeta{ManagedFields: entries}
+		Expect(lastFieldManager(&obj)).To(Equal(expected))
+	},
+	Entry("no changes", nil, ""),
+	Entry(
+		"a later change by someone else",
+		[]metav1.ManagedFieldsEntry{
+			{Manager: FieldManager, Time: &metav1.Time{Time: time.Unix(100, 0)}},
+			{Manager: "someone-else", Time: &metav1.Time{Time: time.Unix(200, 0)}},
+		},
+		"someone-else",
+	),
+	Entry(
+		"a later change by us",
+		[]metav1.ManagedFieldsEntry{
+			{Manager: "someone-else", Time: &metav1.Time{Time: time.Unix(100, 0)}},
+			{Manager: FieldManager, Time: &metav1.Time{Time: time.Unix(200, 0)}},
+		},
+		FieldManager,
+	),
+	Entry(
+		"changes made at the same time",
+		[]metav1.ManagedFieldsEntry{
+			{Manager: "someone-else", Time: &metav1.Time{Time: time.Unix(100, 0)}},
+			{Manager: FieldManager, Time: &metav1.Time{Time: time.Unix(100, 0)}},
+		},
+		"someone-else",
+	),
+	Entry(
+		"a later status change",
+		[]metav1.ManagedFieldsEntry{
+			{Manager: FieldManager, Time: &metav1.Time{Time: time.Unix(100, 0)}},
+			{Manager: "someone-else", Time: &metav1.Time{Time: time.Unix(200, 0)}, Subresource: "status"},
+		},
+		FieldManager,
+	),
+)
+
+var _ = DescribeTable(
+	"staleResources finds inventory records missing from the current inventory",
+	func(previous, current, expected []projctlv1beta1.ProjectDevelopmentStreamResourceStatus) {
+		Expect(staleResources(previous, current)).To(Equal(expected))
+	},
+	Entry("nothing previously generated", nil, []projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+		{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Application", Name: "app"},
+	}, nil),
+	Entry(
+		"a resource removed from the template",
+		[]projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Application", Name: "app"},
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Component", Name: "comp"},
+		},
+		[]projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Application", Name: "app"},
+		},
+		[]projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Component", Name: "comp"},
+		},
+	),
+	Entry(
+		"a resource with a changed outcome",
+		[]projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Application", Name: "app", Outcome: projctlv1beta1.ResourceError},
+		},
+		[]projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Application", Name: "app", Outcome: projctlv1beta1.ResourceApplied},
+		},
+		nil,
+	),
+	Entry(
+		"a resource whose API version changed",
+		[]projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "IntegrationTestScenario", Name: "its"},
+		},
+		[]projctlv1beta1.ProjectDevelopmentStreamResourceStatus{
+			{APIVersion: "appstudio.redhat.com/v1beta2", Kind: "IntegrationTestScenario", Name: "its"},
+		},
+		nil,
+	),
+)
+
+var _ = Describe("Pinning a stream to a template revision", func() {
+	var (
+		ctx        context.Context
+		testNs     string
+		testNsN    types.NamespacedName
+		pdstNsN    types.NamespacedName
+		reconciler *ProjectDevelopmentStreamReconciler
+		pdstRec    *ProjectDevelopmentStreamTemplateReconciler
+	)
+
+	reconcileAll := func() {
+		_, err := pdstRec.Reconcile(ctx, reconcile.Request{NamespacedName: pdstNsN})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: testNsN})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	getDisplayName := func() any {
+		app := &unstructured.Unstructured{}
+		app.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+		app.SetKind("Application")
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testNs, Name: "cool-app-2-2-0"}, app)).To(Succeed())
+		return app.Object["spec"].(map[string]any)["displayName"]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		testNs = setupTestNamespace(ctx, k8sClient)
+		testNsN = types.NamespacedName{Namespace: testNs, Name: "pds-sample-w-imagerepo"}
+		pdstNsN = types.NamespacedName{Namespace: testNs, Name: "pdst-sample-w-imagerepo"}
+
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_project.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pdst_w_imagerepo.yaml", testNs)
+		applySampleFile(ctx, k8sClient, "projctl_v1beta1_pds_w_imagerepo.yaml", testNs)
+
+		reconciler = &ProjectDevelopmentStreamReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStream-controller-tests"),
+		}
+		pdstRec = &ProjectDevelopmentStreamTemplateReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStreamTemplate-controller-tests"),
+		}
+
+		// First reconcile sets the owner reference, second creates resources
+		for range 2 {
+			reconcileAll()
+		}
+	})
+
+	It("keeps using the pinned revision when the template changes", func() {
+		pds := getPDS(ctx, k8sClient, testNsN)
+		Expect(pds.Status.TemplateRevision).To(Equal("pdst-sample-w-imagerepo-1"))
+		pds.Spec.Template.Revision = "1"
+		Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+
+		var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+		Expect(k8sClient.Get(ctx, pdstNsN, &pdst)).To(Succeed())
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object, "Even Cooler App {{.version}}", "spec", "displayName",
+		)).To(Succeed())
+		Expect(k8sClient.Update(ctx, &pdst)).To(Succeed())
+		reconcileAll()
+
+		Expect(getDisplayName()).To(Equal("Cool App 2.2.0"))
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.TemplateRevision).To(Equal("pdst-sample-w-imagerepo-1"))
+
+		By("Using the current template spec once unpinned")
+		pds = getPDS(ctx, k8sClient, testNsN)
+		pds.Spec.Template.Revision = projctlv1beta1.TemplateRevisionLatest
+		Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+		reconcileAll()
+
+		Expect(getDisplayName()).To(Equal("Even Cooler App 2.2.0"))
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.TemplateRevision).To(Equal("pdst-sample-w-imagerepo-2"))
+	})
+
+	Context("When the template extends another template", func() {
+		var baseNsN types.NamespacedName
+
+		getBaseDisplayName := func() any {
+			app := &unstructured.Unstructured{}
+			app.SetAPIVersion("appstudio.redhat.com/v1alpha1")
+			app.SetKind("Application")
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testNs, Name: "base-app-2-2-0"}, app)).To(Succeed())
+			return app.Object["spec"].(map[string]any)["displayName"]
+		}
+
+		updateBase := func() {
+			var base projctlv1beta1.ProjectDevelopmentStreamTemplate
+			Expect(k8sClient.Get(ctx, baseNsN, &base)).To(Succeed())
+			Expect(unstructured.SetNestedField(
+				base.Spec.Resources[0].Object, "Better Base App", "spec", "displayName",
+			)).To(Succeed())
+			Expect(k8sClient.Update(ctx, &base)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			baseNsN = types.NamespacedName{Namespace: testNs, Name: "pdst-base"}
+			base := &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: baseNsN.Namespace, Name: baseNsN.Name},
+				Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+					Resources: []projctlv1beta1.UnstructuredObj{{Unstructured: unstructured.Unstructured{
+						Object: map[string]any{
+							"apiVersion": "appstudio.redhat.com/v1alpha1",
+							"kind":       "Application",
+							"metadata":   map[string]any{"name": "base-app-{{.versionName}}"},
+							"spec":       map[string]any{"displayName": "Base App"},
+						},
+					}}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, base)).To(Succeed())
+		})
+
+		// Make the template extend the base template with the given rollout
+		// strategy, and apply the result
+		extendBase := func(rollout *projctlv1beta1.RolloutStrategy) {
+			var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+			Expect(k8sClient.Get(ctx, pdstNsN, &pdst)).To(Succeed())
+			pdst.Spec.Extends = []projctlv1beta1.TemplateReference{{Name: baseNsN.Name}}
+			pdst.Spec.Rollout = rollout
+			Expect(k8sClient.Update(ctx, &pdst)).To(Succeed())
+			reconcileAll()
+
+			Expect(getBaseDisplayName()).To(Equal("Base App"))
+			Expect(getPDS(ctx, k8sClient, testNsN).Status.TemplateRevision).To(Equal("pdst-sample-w-imagerepo-2.1"))
+		}
+
+		It("keeps using the pinned revision when the base template changes", func() {
+			extendBase(nil)
+			var rev projctlv1beta1.ProjectDevelopmentStreamTemplateRevision
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testNs, Name: "pdst-sample-w-imagerepo-2.1"}, &rev)).To(Succeed())
+			Expect(rev.Spec.Extends).To(BeEmpty())
+			Expect(rev.Spec.Resources).To(HaveLen(4))
+			Expect(rev.Bases).To(HaveExactElements(projctlv1beta1.TemplateBase{
+				Kind: projctlv1beta1.TemplateKindNamespaced, Name: baseNsN.Name, Generation: 1,
+			}))
+
+			pds := getPDS(ctx, k8sClient, testNsN)
+			pds.Spec.Template.Revision = "2.1"
+			Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+			updateBase()
+			reconcileAll()
+
+			Expect(getBaseDisplayName()).To(Equal("Base App"))
+			Expect(getPDS(ctx, k8sClient, testNsN).Status.TemplateRevision).To(Equal("pdst-sample-w-imagerepo-2.1"))
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testNs, Name: "pdst-sample-w-imagerepo-2.2"}, &rev)).To(Succeed())
+
+			By("Using the current base template spec once unpinned")
+			pds = getPDS(ctx, k8sClient, testNsN)
+			pds.Spec.Template.Revision = projctlv1beta1.TemplateRevisionLatest
+			Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+			reconcileAll()
+
+			Expect(getBaseDisplayName()).To(Equal("Better Base App"))
+			Expect(getPDS(ctx, k8sClient, testNsN).Status.TemplateRevision).To(Equal("pdst-sample-w-imagerepo-2.2"))
+		})
+
+		It("rolls out base template changes according to the rollout strategy", func() {
+			extendBase(&projctlv1beta1.RolloutStrategy{Canaries: []string{"pds-canary"}})
+
+			// A canary stream that never gets updated holds the rollout back
+			canary := &projctlv1beta1.ProjectDevelopmentStream{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNs, Name: "pds-canary"},
+				Spec: projctlv1beta1.ProjectDevelopmentStreamSpec{
+					Project: "project-sample",
+					Template: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+						Name: pdstNsN.Name,
+						Values: []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+							{Name: "version", Value: "3.0.0"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, canary)).To(Succeed())
+			updateBase()
+			reconcileAll()
+
+			Expect(getBaseDisplayName()).To(Equal("Base App"))
+			pds := getPDS(ctx, k8sClient, testNsN)
+			Expect(pds.Status.TemplateRevision).To(Equal("pdst-sample-w-imagerepo-2.1"))
+			Expect(pds.Status.Conditions).To(ContainElement(And(
+				HaveField("Type", ConditionTypeReady),
+				HaveField("Status", metav1.ConditionUnknown),
+				HaveField("Reason", "RolloutPending"),
+			)))
+		})
+	})
+
+	It("reports missing revisions", func() {
+		pds := getPDS(ctx, k8sClient, testNsN)
+		pds.Spec.Template.Revision = "5"
+		Expect(k8sClient.Update(ctx, &pds)).To(Succeed())
+		reconcileAll()
+
+		Expect(getPDS(ctx, k8sClient, testNsN).Status.Conditions).To(ContainElement(And(
+			HaveField("Type", ConditionTypeReady),
+			HaveField("Status", metav1.ConditionFalse),
+			HaveField("Reason", "TemplateFetchFailed"),
+		)))
+	})
+})
+
+var _ = Describe("ProjectDevelopmentStream validation", func() {
+	It("rejects template values that set both value and valueFrom", func() {
+		ctx := context.Background()
+		testNs := setupTestNamespace(ctx, k8sClient)
+		pds := &projctlv1beta1.ProjectDevelopmentStream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNs, Name: "both-values"},
+			Spec: projctlv1beta1.ProjectDevelopmentStreamSpec{
+				Project: "my-project",
+				Template: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+					Name: "my-template",
+					Values: []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{{
+						Name:  "version",
+						Value: "1.0.0",
+						ValueFrom: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource{
+							ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+								Key:                  "version",
+							},
+						},
+					}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pds)).To(MatchError(ContainSubstring("value and valueFrom are mutually exclusive")))
+
+		By("Accepting values read from a ConfigMap alone")
+		pds.Spec.Template.Values[0].Value = ""
+		Expect(k8sClient.Create(ctx, pds)).To(Succeed())
+	})
+})
@@ -0,0 +1,150 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+	"github.com/konflux-ci/project-controller/pkg/logr/eventr"
+	"github.com/konflux-ci/project-controller/pkg/logr/muxr"
+)
+
+const (
+	// ConditionTypeValid represents the Valid condition type
+	ConditionTypeValid = "Valid"
+)
+
+// ProjectDevelopmentStreamTemplateReconciler reconciles a
+// ProjectDevelopmentStreamTemplate object
+type ProjectDevelopmentStreamTemplateReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+	// If set, all templates get validated again when an event is received on
+	// this channel, which happens when the supported resource types change
+	ResourceTypesChanged <-chan event.GenericEvent
+}
+
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplates/finalizers,verbs=update
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=clusterprojectdevelopmentstreamtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreams,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplaterevisions,verbs=get;list;watch;create;delete
+
+// Reconcile validates a ProjectDevelopmentStreamTemplate, records a revision
+// of its current spec, prunes old revisions, and updates its status with the
+// validation results and the streams that use it
+func (r *ProjectDevelopmentStreamTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+	if err := r.Get(ctx, req.NamespacedName, &pdst); err != nil {
+		logger.Error(err, "Unable to fetch ProjectDevelopmentStreamTemplate")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	logger = logger.WithValues("PDS Template", pdst.Name)
+	logger = muxr.NewMuxLogger(logger, eventr.NewEventr(r.Recorder, &pdst))
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	return ctrl.Result{}, reconcileTemplate(ctx, r.Client, r.Scheme, &pdst)
+}
+
+// Returns a handler for collecting the template a dev stream uses
+func getStreamTemplateEventHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			pds, ok := o.(*projctlv1beta1.ProjectDevelopmentStream)
+			if !ok || pds.Spec.Template == nil || template.RefKind(pds.Spec.Template) != projctlv1beta1.TemplateKindNamespaced {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{
+				Namespace: pds.GetNamespace(),
+				Name:      pds.Spec.Template.Name,
+			}}}
+		},
+	)
+}
+
+// Returns a handler for collecting the templates of kind extendingKind that
+// extend a given template of the given kind, directly or via other templates
+func getExtendingTemplatesEventHandler(
+	c client.Reader, kind, extendingKind projctlv1beta1.TemplateKind,
+) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			lg := log.FromContext(ctx)
+
+			extending, err := extendingTemplates(ctx, c, template.ID{Kind: kind, Namespace: o.GetNamespace(), Name: o.GetName()})
+			if err != nil {
+				lg.Error(err, "Failed listing templates extending template")
+				return nil
+			}
+			var ret []reconcile.Request
+			for _, key := range extending {
+				if key.Kind == extendingKind {
+					ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: key.Namespace, Name: key.Name}})
+				}
+			}
+			return ret
+		},
+	)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProjectDevelopmentStreamTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&projctlv1beta1.ProjectDevelopmentStreamTemplate{}).
+		Owns(&projctlv1beta1.ProjectDevelopmentStreamTemplateRevision{}).
+		// When a stream switches templates, the map function gets called for
+		// both the old and the new stream objects, so both templates get
+		// updated
+		Watches(
+			&projctlv1beta1.ProjectDevelopmentStream{},
+			getStreamTemplateEventHandler(),
+		).
+		// Templates are validated with the templates they extend merged into
+		// them
+		Watches(
+			&projctlv1beta1.ProjectDevelopmentStreamTemplate{},
+			getExtendingTemplatesEventHandler(r.Client, projctlv1beta1.TemplateKindNamespaced, projctlv1beta1.TemplateKindNamespaced),
+		).
+		Watches(
+			&projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{},
+			getExtendingTemplatesEventHandler(r.Client, projctlv1beta1.TemplateKindCluster, projctlv1beta1.TemplateKindNamespaced),
+		)
+	if r.ResourceTypesChanged != nil {
+		bldr = bldr.WatchesRawSource(source.Channel(
+			r.ResourceTypesChanged,
+			getAllObjectsEventHandler(r.Client, &projctlv1beta1.ProjectDevelopmentStreamTemplateList{}),
+		))
+	}
+	return bldr.Complete(r)
+}
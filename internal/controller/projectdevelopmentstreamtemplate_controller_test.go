@@ -0,0 +1,164 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("ProjectDevelopmentStreamTemplate Controller", func() {
+	ctx := context.Background()
+
+	var testNs string
+	var reconciler *ProjectDevelopmentStreamTemplateReconciler
+
+	BeforeEach(func() {
+		testNs = setupTestNamespace(ctx, k8sClient)
+
+		for _, resFile := range []string{
+			"projctl_v1beta1_project.yaml",
+			"projctl_v1beta1_projectdevelopmentstreamtemplate.yaml",
+			"projctl_v1beta1_projectdevelopmentstream_w_template_vars.yaml",
+			"projctl_v1beta1_pdst_invalid_template.yaml",
+			"projctl_v1beta1_pds_invalid_template.yaml",
+			"projctl_v1beta1_pds_no_template.yaml",
+		} {
+			applySampleFile(ctx, k8sClient, resFile, testNs)
+		}
+
+		reconciler = &ProjectDevelopmentStreamTemplateReconciler{
+			Client:   saClient,
+			Scheme:   saClient.Scheme(),
+			Recorder: saCluster.GetEventRecorder("ProjectDevelopmentStreamTemplate-controller-tests"),
+		}
+	})
+
+	It("should report a valid template and the streams using it", func() {
+		pdstNsN := types.NamespacedName{Namespace: testNs, Name: "projectdevelopmentstreamtemplate-sample"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdstNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		pdst := &projctlv1beta1.ProjectDevelopmentStreamTemplate{}
+		Expect(k8sClient.Get(ctx, pdstNsN, pdst)).To(Succeed())
+		Expect(pdst.Status.Conditions).To(HaveLen(1))
+		Expect(pdst.Status.Conditions[0].Type).To(Equal(ConditionTypeValid))
+		Expect(pdst.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+		Expect(pdst.Status.Conditions[0].Reason).To(Equal("TemplateValid"))
+		Expect(pdst.Status.DevelopmentStreams).To(Equal([]string{
+			"projectdevelopmentstream-sample-w-template-vars",
+		}))
+	})
+
+	It("should report the issues found in an invalid template", func() {
+		pdstNsN := types.NamespacedName{Namespace: testNs, Name: "pdst-invalid-template"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdstNsN})
+		Expect(err).NotTo(HaveOccurred())
+		checkExpectedFile(ctx, k8sClient, "projctl_v1beta1_pdst_invalid_template_exp_results.yaml", testNs)
+	})
+
+	It("should record a revision for each template generation", func() {
+		pdstNsN := types.NamespacedName{Namespace: testNs, Name: "projectdevelopmentstreamtemplate-sample"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdstNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		pdst := &projctlv1beta1.ProjectDevelopmentStreamTemplate{}
+		Expect(k8sClient.Get(ctx, pdstNsN, pdst)).To(Succeed())
+		oldSpec := pdst.Spec.DeepCopy()
+		pdst.Spec.Variables[0].Description = "A version number"
+		Expect(k8sClient.Update(ctx, pdst)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdstNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		rev := &projctlv1beta1.ProjectDevelopmentStreamTemplateRevision{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNs, Name: pdstNsN.Name + "-1"}, rev)).To(Succeed())
+		Expect(rev.Revision).To(BeEquivalentTo(1))
+		Expect(rev.Spec).To(Equal(*oldSpec))
+		Expect(metav1.IsControlledBy(rev, pdst)).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNs, Name: pdstNsN.Name + "-2"}, rev)).To(Succeed())
+		Expect(rev.Revision).To(BeEquivalentTo(2))
+		Expect(rev.Spec).To(Equal(pdst.Spec))
+	})
+
+	It("should not record revisions of an invalid template", func() {
+		pdstNsN := types.NamespacedName{Namespace: testNs, Name: "pdst-invalid-template"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdstNsN})
+		Expect(err).NotTo(HaveOccurred())
+
+		var list projctlv1beta1.ProjectDevelopmentStreamTemplateRevisionList
+		Expect(k8sClient.List(ctx, &list, client.InNamespace(testNs))).To(Succeed())
+		Expect(list.Items).To(BeEmpty())
+	})
+
+	It("should prune old revisions that no stream is pinned to", func() {
+		pdstNsN := types.NamespacedName{Namespace: testNs, Name: "projectdevelopmentstreamtemplate-sample"}
+		pdsNsN := types.NamespacedName{Namespace: testNs, Name: "projectdevelopmentstream-sample-w-template-vars"}
+		reconcileTemplate := func() {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: pdstNsN})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		updateTemplate := func(update func(*projctlv1beta1.ProjectDevelopmentStreamTemplate)) {
+			pdst := &projctlv1beta1.ProjectDevelopmentStreamTemplate{}
+			Expect(k8sClient.Get(ctx, pdstNsN, pdst)).To(Succeed())
+			update(pdst)
+			Expect(k8sClient.Update(ctx, pdst)).To(Succeed())
+			reconcileTemplate()
+		}
+		revisionNames := func() []string {
+			var list projctlv1beta1.ProjectDevelopmentStreamTemplateRevisionList
+			Expect(k8sClient.List(ctx, &list, client.InNamespace(testNs))).To(Succeed())
+			var names []string
+			for _, rev := range list.Items {
+				names = append(names, rev.Name)
+			}
+			return names
+		}
+
+		reconcileTemplate()
+		updateTemplate(func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+			pdst.Spec.RevisionHistoryLimit = new(int32(1))
+		})
+		Expect(revisionNames()).To(ConsistOf(pdstNsN.Name+"-1", pdstNsN.Name+"-2"))
+
+		pds := &projctlv1beta1.ProjectDevelopmentStream{}
+		Expect(k8sClient.Get(ctx, pdsNsN, pds)).To(Succeed())
+		pds.Spec.Template.Revision = "1"
+		Expect(k8sClient.Update(ctx, pds)).To(Succeed())
+		for _, description := range []string{"A version", "A version number"} {
+			updateTemplate(func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Variables[0].Description = description
+			})
+		}
+		Expect(revisionNames()).To(ConsistOf(pdstNsN.Name+"-1", pdstNsN.Name+"-3", pdstNsN.Name+"-4"))
+
+		By("Pruning revisions once streams are no longer pinned to them")
+		Expect(k8sClient.Get(ctx, pdsNsN, pds)).To(Succeed())
+		pds.Spec.Template.Revision = ""
+		Expect(k8sClient.Update(ctx, pds)).To(Succeed())
+		reconcileTemplate()
+		Expect(revisionNames()).To(ConsistOf(pdstNsN.Name+"-3", pdstNsN.Name+"-4"))
+	})
+})
@@ -0,0 +1,240 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+const (
+	// ConditionTypeAccepted represents the Accepted condition type
+	ConditionTypeAccepted = "Accepted"
+)
+
+// ResourceTypePolicyReconciler merges all the ResourceTypePolicy objects in
+// the cluster into the resource types supported by templates
+type ResourceTypePolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Resource type policies loaded from the controller configuration. These
+	// are merged before, and take precedence over, ResourceTypePolicy objects
+	ConfiguredPolicies []projctlv1beta1.ResourceTypePolicySpec
+	// Channels that get notified when the supported resource types change, so
+	// that objects depending on them can be reconciled again. Notifications
+	// are dropped rather than waited for when a channel is full, so channels
+	// are expected to be buffered, since pending notifications are enough to
+	// have everything reconciled again
+	ResourceTypesChanged []chan<- event.GenericEvent
+	// Closed once the manager this reconciler runs in is elected leader.
+	// Policies get merged in every replica so that the webhooks running there
+	// see them, but their status is only updated by the leader. If nil, the
+	// status is always updated.
+	Elected <-chan struct{}
+}
+
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=resourcetypepolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=projctl.konflux.dev,resources=resourcetypepolicies/status,verbs=get;update;patch
+
+// Reconcile merges all the ResourceTypePolicy objects into the supported
+// resource types and reports in the status of each one whether it was
+// accepted. Since policies are merged together, every policy gets processed
+// regardless of the one the request is about.
+//
+// This controller runs in every replica, not only in the elected leader,
+// since the supported resource types are kept in memory.
+func (r *ResourceTypePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var policyList projctlv1beta1.ResourceTypePolicyList
+	if err := r.List(ctx, &policyList); err != nil {
+		logger.Error(err, "Failed listing resource type policies")
+		return ctrl.Result{}, err
+	}
+	policies := policyList.Items
+	slices.SortFunc(policies, func(a, b projctlv1beta1.ResourceTypePolicy) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	accepted := slices.Clone(r.ConfiguredPolicies)
+	conditions := make([]metav1.Condition, len(policies))
+	for i, policy := range policies {
+		conditions[i] = metav1.Condition{
+			Type:    ConditionTypeAccepted,
+			Status:  metav1.ConditionTrue,
+			Reason:  "PolicyAccepted",
+			Message: "Policy is in effect",
+		}
+		if err := template.CheckResourceTypePolicy(accepted, policy.Spec); err != nil {
+			logger.Info("Resource type policy rejected", "policy", policy.Name, "reason", err.Error())
+			conditions[i].Status = metav1.ConditionFalse
+			conditions[i].Reason = "PolicyConflict"
+			conditions[i].Message = err.Error()
+			continue
+		}
+		accepted = append(accepted, policy.Spec)
+	}
+
+	if template.SetResourceTypePolicies(accepted) {
+		logger.Info("Supported resource types changed")
+		for _, ch := range r.ResourceTypesChanged {
+			select {
+			case ch <- event.GenericEvent{Object: &projctlv1beta1.ResourceTypePolicy{}}:
+			default:
+			}
+		}
+	}
+
+	if !r.isLeader() {
+		return ctrl.Result{}, nil
+	}
+
+	for i := range policies {
+		if err := r.applyStatus(ctx, &policies[i], conditions[i]); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// Check whether the status of the policies may be updated by this replica
+func (r *ResourceTypePolicyReconciler) isLeader() bool {
+	if r.Elected == nil {
+		return true
+	}
+	select {
+	case <-r.Elected:
+		return true
+	default:
+		return false
+	}
+}
+
+// applyStatus sets the given condition on the policy status using
+// server-side apply
+func (r *ResourceTypePolicyReconciler) applyStatus(
+	ctx context.Context,
+	policy *projctlv1beta1.ResourceTypePolicy,
+	condition metav1.Condition,
+) error {
+	logger := log.FromContext(ctx)
+
+	condition.ObservedGeneration = policy.Generation
+	condition.LastTransitionTime = metav1.Now()
+	// Preserve LastTransitionTime when status hasn't changed per Kubernetes API conventions.
+	existing := meta.FindStatusCondition(policy.Status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+
+	gvk, err := r.GroupVersionKindFor(policy)
+	if err != nil {
+		logger.Error(err, "Failed to get GVK for ResourceTypePolicy")
+		return err
+	}
+	applyStatus := &projctlv1beta1.ResourceTypePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: policy.Name},
+		Status: projctlv1beta1.ResourceTypePolicyStatus{
+			Conditions: []metav1.Condition{condition},
+		},
+	}
+	applyStatus.GetObjectKind().SetGroupVersionKind(gvk)
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(applyStatus)
+	if err != nil {
+		logger.Error(err, "Failed to convert status to unstructured")
+		return err
+	}
+	applyObj := &unstructured.Unstructured{Object: u}
+	if err := r.Status().Apply(ctx, client.ApplyConfigurationFromUnstructured(applyObj), client.FieldOwner(FieldManager)); err != nil {
+		logger.Error(err, "Failed to update ResourceTypePolicy status", "policy", policy.Name)
+		return err
+	}
+	return nil
+}
+
+// Returns a handler that maps any object to all the objects of the given list
+// type in the cluster, used for reconciling all streams or templates when the
+// supported resource types change
+func getAllObjectsEventHandler(c client.Client, list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			lg := log.FromContext(ctx)
+
+			objList := list.DeepCopyObject().(client.ObjectList)
+			if err := c.List(ctx, objList); err != nil {
+				lg.Error(err, "Failed listing objects")
+				return nil
+			}
+			var ret []reconcile.Request
+			_ = meta.EachListItem(objList, func(obj runtime.Object) error {
+				if o, ok := obj.(client.Object); ok {
+					ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+				}
+				return nil
+			})
+			return ret
+		},
+	)
+}
+
+// SetupWithManager sets up the controller with the Manager. The controller
+// does not need leader election.
+func (r *ResourceTypePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(
+			&projctlv1beta1.ResourceTypePolicy{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		WithOptions(controller.Options{NeedLeaderElection: new(false)})
+	if r.Elected != nil {
+		// Policies are reconciled once more when the replica gets elected, so
+		// their status gets updated even if they did not change since
+		bldr = bldr.WatchesRawSource(source.Func(
+			func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+				go func() {
+					select {
+					case <-r.Elected:
+						q.Add(reconcile.Request{})
+					case <-ctx.Done():
+					}
+				}()
+				return nil
+			},
+		))
+	}
+	return bldr.Complete(r)
+}
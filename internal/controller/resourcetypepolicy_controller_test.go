@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+var _ = Describe("ResourceTypePolicy Controller", func() {
+	ctx := context.Background()
+
+	mkPolicy := func(name, kind string) *projctlv1beta1.ResourceTypePolicy {
+		policy := &projctlv1beta1.ResourceTypePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: projctlv1beta1.ResourceTypePolicySpec{
+				APIs: []projctlv1beta1.ResourceTypeAPI{
+					{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: kind},
+				},
+				TemplatableNameFields: []projctlv1beta1.FieldPath{{"metadata", "name"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, policy)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, ctx, policy)
+		return policy
+	}
+
+	getAccepted := func(name string) *metav1.Condition {
+		policy := &projctlv1beta1.ResourceTypePolicy{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name}, policy)).To(Succeed())
+		return meta.FindStatusCondition(policy.Status.Conditions, ConditionTypeAccepted)
+	}
+
+	It("merges the policies into the supported resource types", func() {
+		DeferCleanup(func() { template.SetResourceTypePolicies(nil) })
+		mkPolicy("a-rpa", "ReleasePlanAdmission")
+		mkPolicy("b-rpa", "ReleasePlanAdmission")
+		mkPolicy("c-app", "Application")
+
+		changes := make(chan event.GenericEvent, 1)
+		reconciler := &ResourceTypePolicyReconciler{
+			Client:               saClient,
+			Scheme:               saClient.Scheme(),
+			ResourceTypesChanged: []chan<- event.GenericEvent{changes},
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "a-rpa"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(Receive())
+
+		Expect(getAccepted("a-rpa")).To(HaveField("Status", metav1.ConditionTrue))
+		Expect(getAccepted("c-app")).To(HaveField("Status", metav1.ConditionTrue))
+		conflict := getAccepted("b-rpa")
+		Expect(conflict.Status).To(Equal(metav1.ConditionFalse))
+		Expect(conflict.Reason).To(Equal("PolicyConflict"))
+		Expect(conflict.Message).To(ContainSubstring("already defined by another policy"))
+
+		By("Not notifying when nothing changed")
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "b-rpa"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).NotTo(Receive())
+	})
+
+	It("merges the policies without updating their status until elected", func() {
+		DeferCleanup(func() { template.SetResourceTypePolicies(nil) })
+		mkPolicy("rpa", "ReleasePlanAdmission")
+
+		// A full channel does not hold the reconciler up
+		changes := make(chan event.GenericEvent, 1)
+		changes <- event.GenericEvent{}
+		elected := make(chan struct{})
+		reconciler := &ResourceTypePolicyReconciler{
+			Client:               saClient,
+			Scheme:               saClient.Scheme(),
+			ResourceTypesChanged: []chan<- event.GenericEvent{changes},
+			Elected:              elected,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(template.ResourceAPIs()).To(ContainElement(HaveField("Kind", "ReleasePlanAdmission")))
+		Expect(getAccepted("rpa")).To(BeNil())
+
+		close(elected)
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAccepted("rpa")).To(HaveField("Status", metav1.ConditionTrue))
+	})
+})
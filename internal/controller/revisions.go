@@ -0,0 +1,155 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+// The number of earlier revisions kept for templates that do not set a
+// revision history limit
+const defaultRevisionHistoryLimit = 10
+
+// Create the given revision of the given template, named after the template
+// and the given revision ID, unless it exists already. Revisions are owned by
+// their template, so they get deleted along with it.
+func createTemplateRevision(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	tmpl client.Object,
+	id string,
+	revision client.Object,
+) error {
+	revision.SetNamespace(tmpl.GetNamespace())
+	revision.SetName(template.RevisionName(tmpl.GetName(), id))
+	if err := controllerutil.SetControllerReference(tmpl, revision, scheme); err != nil {
+		return err
+	}
+	if err := c.Create(ctx, revision); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// Delete the earlier revisions of the given template beyond the given limit,
+// oldest first, keeping the current revision and the revisions streams are
+// pinned to. Revisions are listed into the given list, which determines their
+// kind.
+func pruneTemplateRevisions(
+	ctx context.Context,
+	c client.Client,
+	tmpl client.Object,
+	limit *int32,
+	current string,
+	pinned []string,
+	list client.ObjectList,
+) error {
+	var opts []client.ListOption
+	if tmpl.GetNamespace() != "" {
+		opts = append(opts, client.InNamespace(tmpl.GetNamespace()))
+	}
+	if err := c.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	var earlier []client.Object
+	_ = meta.EachListItem(list, func(obj runtime.Object) error {
+		if rev, ok := obj.(client.Object); ok && metav1.IsControlledBy(rev, tmpl) && rev.GetName() != current {
+			earlier = append(earlier, rev)
+		}
+		return nil
+	})
+	keep := defaultRevisionHistoryLimit
+	if limit != nil {
+		keep = int(*limit)
+	}
+	if len(earlier) <= keep {
+		return nil
+	}
+	// Newest first
+	slices.SortFunc(earlier, func(a, b client.Object) int {
+		return cmp.Or(
+			cmp.Compare(revisionGeneration(b), revisionGeneration(a)),
+			b.GetCreationTimestamp().Compare(a.GetCreationTimestamp().Time),
+			cmp.Compare(b.GetName(), a.GetName()),
+		)
+	})
+	for _, rev := range earlier[keep:] {
+		if slices.Contains(pinned, rev.GetName()) {
+			continue
+		}
+		if err := c.Delete(ctx, rev); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the generation of the template the given revision was taken from
+func revisionGeneration(rev client.Object) int64 {
+	switch rev := rev.(type) {
+	case *projctlv1beta1.ProjectDevelopmentStreamTemplateRevision:
+		return rev.Revision
+	case *projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision:
+		return rev.Revision
+	}
+	return 0
+}
+
+// Returns the names of the revisions of the template with the given kind and
+// name the given streams are pinned to
+func pinnedRevisions(
+	streams []projctlv1beta1.ProjectDevelopmentStream,
+	kind projctlv1beta1.TemplateKind,
+	name string,
+) []string {
+	var pinned []string
+	for i := range streams {
+		if template.UsesTemplate(&streams[i], kind, name) && template.IsPinned(streams[i].Spec.Template) {
+			pinned = append(pinned, template.RevisionName(name, streams[i].Spec.Template.Revision))
+		}
+	}
+	return pinned
+}
+
+// Returns a handler for collecting the dev streams that use the template a
+// given template revision of the given kind was taken from
+func getRevisionStreamsEventHandler(r *ProjectDevelopmentStreamReconciler, kind projctlv1beta1.TemplateKind) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			owner := metav1.GetControllerOf(o)
+			if owner == nil || owner.Kind != string(kind) {
+				return nil
+			}
+			return templateStreams(ctx, r, kind, o.GetNamespace(), owner.Name)
+		},
+	)
+}
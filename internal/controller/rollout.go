@@ -0,0 +1,258 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+// Returns the name of the revision of the given template, as returned by
+// template.Get, that streams using it apply. It changes whenever the template
+// or any of the templates it extends does.
+func templateRevision(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) string {
+	return template.RevisionName(pdst.GetName(), template.RevisionID(pdst))
+}
+
+// Check whether the given stream is waiting to apply the given revision of
+// its template. Only streams that applied an earlier revision of the
+// template, and whose own spec did not change since they were last reconciled,
+// wait for rollouts. Streams pinned to a template revision never do.
+func rolloutPending(pds *projctlv1beta1.ProjectDevelopmentStream, revision string) bool {
+	if template.IsPinned(pds.Spec.Template) ||
+		pds.Status.TemplateRevision == "" ||
+		pds.Status.TemplateRevision == revision {
+		return false
+	}
+	ready := meta.FindStatusCondition(pds.Status.Conditions, ConditionTypeReady)
+	return ready != nil && ready.ObservedGeneration == pds.Generation
+}
+
+// Returns how a stream is referred to in the rollout strategy of the given
+// template
+func rolloutStreamRef(pds *projctlv1beta1.ProjectDevelopmentStream, pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) string {
+	if pdst.GetNamespace() == "" {
+		return client.ObjectKeyFromObject(pds).String()
+	}
+	return pds.GetName()
+}
+
+// Check whether the given stream may apply the current revision of the given
+// template according to the template's rollout strategy, given all the streams
+// that use the template. Returns an empty string if it may, or a description
+// of what the stream is waiting for otherwise.
+func rolloutBlocker(
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+	pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+	streams []projctlv1beta1.ProjectDevelopmentStream,
+) string {
+	rollout := pdst.Spec.Rollout
+	revision := templateRevision(pdst)
+	if rollout == nil || !rolloutPending(pds, revision) {
+		return ""
+	}
+	isCanary := func(s *projctlv1beta1.ProjectDevelopmentStream) bool {
+		return slices.Contains(rollout.Canaries, rolloutStreamRef(s, pdst))
+	}
+
+	var pending []*projctlv1beta1.ProjectDevelopmentStream
+	var unavailable, failed int
+	canariesReady := true
+	for i := range streams {
+		stream := &streams[i]
+		if template.IsPinned(stream.Spec.Template) {
+			continue
+		}
+		if stream.Status.TemplateRevision != revision {
+			if isCanary(stream) {
+				canariesReady = false
+			}
+			if rolloutPending(stream, revision) {
+				pending = append(pending, stream)
+			}
+			continue
+		}
+		switch readyStatus(stream) {
+		case metav1.ConditionTrue:
+			continue
+		case metav1.ConditionFalse:
+			failed++
+		default:
+			unavailable++
+		}
+		if isCanary(stream) {
+			canariesReady = false
+		}
+	}
+
+	if rollout.HaltOnFailure && failed > 0 {
+		return fmt.Sprintf("rollout halted because %d streams failed to apply it", failed)
+	}
+	if !isCanary(pds) && !canariesReady {
+		return "waiting for the canary streams to be updated"
+	}
+	maxUnavailable := 1
+	if rollout.MaxUnavailable != nil {
+		maxUnavailable = int(*rollout.MaxUnavailable)
+	}
+	// Streams get updated in a stable order, canaries first
+	slices.SortFunc(pending, func(a, b *projctlv1beta1.ProjectDevelopmentStream) int {
+		if isCanary(a) != isCanary(b) {
+			if isCanary(a) {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(rolloutStreamRef(a, pdst), rolloutStreamRef(b, pdst))
+	})
+	position := slices.IndexFunc(pending, func(s *projctlv1beta1.ProjectDevelopmentStream) bool {
+		return rolloutStreamRef(s, pdst) == rolloutStreamRef(pds, pdst)
+	})
+	if position >= maxUnavailable-unavailable {
+		return fmt.Sprintf("waiting for %d streams being updated", unavailable+max(position, 0))
+	}
+	return ""
+}
+
+// Check whether the given stream may apply the current revision of the given
+// template according to the template's rollout strategy. Returns an empty
+// string if it may, or a description of what the stream is waiting for
+// otherwise.
+func (r *ProjectDevelopmentStreamReconciler) checkRollout(
+	ctx context.Context,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+	pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (string, error) {
+	if pdst.Spec.Rollout == nil || !rolloutPending(pds, templateRevision(pdst)) {
+		return "", nil
+	}
+	kind := template.RefKind(pds.Spec.Template)
+	var opts []client.ListOption
+	if kind == projctlv1beta1.TemplateKindNamespaced {
+		opts = append(opts, client.InNamespace(pds.GetNamespace()))
+	}
+	var list projctlv1beta1.ProjectDevelopmentStreamList
+	if err := r.List(ctx, &list, opts...); err != nil {
+		return "", err
+	}
+	streams := slices.DeleteFunc(list.Items, func(s projctlv1beta1.ProjectDevelopmentStream) bool {
+		return !template.UsesTemplate(&s, kind, pdst.GetName())
+	})
+	return rolloutBlocker(pds, pdst, streams), nil
+}
+
+// Returns a predicate for events about dev streams that may let other streams
+// waiting for a template rollout proceed, i.e. deletions and updates changing
+// the template revision a stream applied or its Ready status
+func rolloutProgressPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPds, ok := e.ObjectOld.(*projctlv1beta1.ProjectDevelopmentStream)
+			if !ok {
+				return false
+			}
+			newPds, ok := e.ObjectNew.(*projctlv1beta1.ProjectDevelopmentStream)
+			if !ok {
+				return false
+			}
+			return oldPds.Status.TemplateRevision != newPds.Status.TemplateRevision ||
+				readyStatus(oldPds) != readyStatus(newPds)
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// Returns the status of the Ready condition of the given stream
+func readyStatus(pds *projctlv1beta1.ProjectDevelopmentStream) metav1.ConditionStatus {
+	if cond := meta.FindStatusCondition(pds.Status.Conditions, ConditionTypeReady); cond != nil {
+		return cond.Status
+	}
+	return metav1.ConditionUnknown
+}
+
+// Returns a handler for collecting, when a dev stream changes, the other
+// streams using the same template that may be waiting for a template rollout,
+// since they may be able to proceed now. Streams are looked up via the
+// StreamTemplateNameIndex field index, and are collected if they are not
+// ready or applied a different template revision than the changed stream.
+// Whether they may proceed is left to Reconcile, so the template does not
+// get fetched here.
+func getRolloutStreamsEventHandler(r *ProjectDevelopmentStreamReconciler) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, o client.Object) []reconcile.Request {
+			lg := log.FromContext(ctx)
+
+			pds, ok := o.(*projctlv1beta1.ProjectDevelopmentStream)
+			if !ok || pds.Spec.Template == nil || template.IsPinned(pds.Spec.Template) {
+				return nil
+			}
+			kind := template.RefKind(pds.Spec.Template)
+			name := pds.Spec.Template.Name
+			list := projctlv1beta1.ProjectDevelopmentStreamList{}
+			opts := []client.ListOption{client.MatchingFields{StreamTemplateNameIndex: name}}
+			if kind == projctlv1beta1.TemplateKindNamespaced {
+				opts = append(opts, client.InNamespace(pds.GetNamespace()))
+			}
+			if err := r.List(ctx, &list, opts...); err != nil {
+				lg.Error(err, "Failed listing dev streams using template")
+				return nil
+			}
+			var ret []reconcile.Request
+			for i := range list.Items {
+				stream := &list.Items[i]
+				if stream.GetUID() != pds.GetUID() &&
+					template.UsesTemplate(stream, kind, name) &&
+					mayWaitForRollout(stream, pds.Status.TemplateRevision) {
+					ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(stream)})
+				}
+			}
+			return ret
+		},
+	)
+}
+
+// Check whether the given stream may be waiting for a rollout of its
+// template, given the template revision another stream using the template
+// applied, without fetching the template. Like rolloutPending, this excludes
+// pinned streams, streams that never applied the template and streams whose
+// own spec changed, but it cannot tell which revision is the current one, so
+// it includes all streams that are not ready or applied a different revision.
+func mayWaitForRollout(pds *projctlv1beta1.ProjectDevelopmentStream, revision string) bool {
+	if template.IsPinned(pds.Spec.Template) || pds.Status.TemplateRevision == "" {
+		return false
+	}
+	ready := meta.FindStatusCondition(pds.Status.Conditions, ConditionTypeReady)
+	if ready == nil || ready.ObservedGeneration != pds.Generation {
+		return false
+	}
+	return ready.Status != metav1.ConditionTrue || pds.Status.TemplateRevision != revision
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("rolloutBlocker", func() {
+	// Make a stream that applied the given template generation and has the
+	// given Ready status
+	mkRolloutStream := func(name string, templateGeneration int64, ready metav1.ConditionStatus) projctlv1beta1.ProjectDevelopmentStream {
+		pds := mkStream(name, "my-project", ready)
+		pds.Namespace = "my-ns"
+		pds.Generation = 1
+		pds.Status.Conditions[0].ObservedGeneration = 1
+		if templateGeneration != 0 {
+			pds.Status.TemplateGeneration = templateGeneration
+			pds.Status.TemplateRevision = fmt.Sprintf("my-template-%d", templateGeneration)
+		}
+		return pds
+	}
+
+	mkTemplate := func(rollout *projctlv1beta1.RolloutStrategy) *projctlv1beta1.ProjectDevelopmentStreamTemplate {
+		pdst := &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-template", Generation: 2},
+		}
+		pdst.Spec.Rollout = rollout
+		return pdst
+	}
+
+	DescribeTable(
+		"decides whether a stream may apply the latest template generation",
+		func(rollout *projctlv1beta1.RolloutStrategy, streams []projctlv1beta1.ProjectDevelopmentStream, stream int, expected string) {
+			Expect(rolloutBlocker(&streams[stream], mkTemplate(rollout), streams)).To(Equal(expected))
+		},
+		Entry(
+			"no rollout strategy",
+			nil,
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 2, metav1.ConditionUnknown),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "",
+		),
+		Entry(
+			"a new stream",
+			&projctlv1beta1.RolloutStrategy{},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 2, metav1.ConditionUnknown),
+				mkRolloutStream("b", 0, metav1.ConditionUnknown),
+			},
+			1, "",
+		),
+		Entry(
+			"the first stream to update",
+			&projctlv1beta1.RolloutStrategy{},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 1, metav1.ConditionTrue),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			0, "",
+		),
+		Entry(
+			"a stream that is not first in line",
+			&projctlv1beta1.RolloutStrategy{},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 1, metav1.ConditionTrue),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "waiting for 1 streams being updated",
+		),
+		Entry(
+			"another stream being updated",
+			&projctlv1beta1.RolloutStrategy{},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 2, metav1.ConditionUnknown),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "waiting for 1 streams being updated",
+		),
+		Entry(
+			"other streams updated",
+			&projctlv1beta1.RolloutStrategy{},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 2, metav1.ConditionTrue),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "",
+		),
+		Entry(
+			"more streams allowed to update at once",
+			&projctlv1beta1.RolloutStrategy{MaxUnavailable: new(int32(2))},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 2, metav1.ConditionUnknown),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "",
+		),
+		Entry(
+			"a failed stream",
+			&projctlv1beta1.RolloutStrategy{},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 2, metav1.ConditionFalse),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "",
+		),
+		Entry(
+			"a failed stream when halting on failure",
+			&projctlv1beta1.RolloutStrategy{HaltOnFailure: true},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 2, metav1.ConditionFalse),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "rollout halted because 1 streams failed to apply it",
+		),
+		Entry(
+			"a canary stream",
+			&projctlv1beta1.RolloutStrategy{Canaries: []string{"b"}},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 1, metav1.ConditionTrue),
+				mkRolloutStream("b", 1, metav1.ConditionTrue),
+			},
+			1, "",
+		),
+		Entry(
+			"waiting for canary streams",
+			&projctlv1beta1.RolloutStrategy{Canaries: []string{"b"}, MaxUnavailable: new(int32(2))},
+			[]projctlv1beta1.ProjectDevelopmentStream{
+				mkRolloutStream("a", 1, metav1.ConditionTrue),
+				mkRolloutStream("b", 2, metav1.ConditionUnknown),
+			},
+			0, "waiting for the canary streams to be updated",
+		),
+	)
+
+	It("does not hold back streams whose own spec changed", func() {
+		streams := []projctlv1beta1.ProjectDevelopmentStream{
+			mkRolloutStream("a", 2, metav1.ConditionUnknown),
+			mkRolloutStream("b", 1, metav1.ConditionTrue),
+		}
+		streams[1].Generation = 2
+		Expect(rolloutBlocker(&streams[1], mkTemplate(&projctlv1beta1.RolloutStrategy{}), streams)).To(BeEmpty())
+	})
+
+	It("does not count streams held back by the rollout as being updated", func() {
+		streams := []projctlv1beta1.ProjectDevelopmentStream{
+			mkRolloutStream("a", 1, metav1.ConditionUnknown),
+			mkRolloutStream("b", 1, metav1.ConditionUnknown),
+		}
+		rollout := &projctlv1beta1.RolloutStrategy{}
+		Expect(rolloutBlocker(&streams[0], mkTemplate(rollout), streams)).To(BeEmpty())
+		Expect(rolloutBlocker(&streams[1], mkTemplate(rollout), streams)).To(Equal("waiting for 1 streams being updated"))
+	})
+
+	It("rolls out changes to the templates a template extends", func() {
+		streams := []projctlv1beta1.ProjectDevelopmentStream{
+			mkRolloutStream("a", 2, metav1.ConditionTrue),
+			mkRolloutStream("b", 2, metav1.ConditionTrue),
+		}
+		pdst := mkTemplate(&projctlv1beta1.RolloutStrategy{})
+		pdst.Status.Bases = []projctlv1beta1.TemplateBase{
+			{Kind: projctlv1beta1.TemplateKindNamespaced, Name: "my-base", Generation: 4},
+		}
+		Expect(rolloutBlocker(&streams[0], pdst, streams)).To(BeEmpty())
+		Expect(rolloutBlocker(&streams[1], pdst, streams)).To(Equal("waiting for 1 streams being updated"))
+
+		streams[0].Status.TemplateRevision = "my-template-2.4"
+		Expect(rolloutBlocker(&streams[1], pdst, streams)).To(BeEmpty())
+	})
+
+	It("ignores streams pinned to a template revision", func() {
+		streams := []projctlv1beta1.ProjectDevelopmentStream{
+			mkRolloutStream("a", 1, metav1.ConditionTrue),
+			mkRolloutStream("b", 1, metav1.ConditionTrue),
+		}
+		streams[0].Spec.Template = &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+			Name: "my-template", Revision: "1",
+		}
+		rollout := &projctlv1beta1.RolloutStrategy{Canaries: []string{"a"}}
+		Expect(rolloutBlocker(&streams[0], mkTemplate(rollout), streams)).To(BeEmpty())
+		Expect(rolloutBlocker(&streams[1], mkTemplate(rollout), streams)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("mayWaitForRollout", func() {
+	// Make a stream that applied the given template revision and has the
+	// given Ready status
+	mkRolloutStream := func(revision string, ready metav1.ConditionStatus) *projctlv1beta1.ProjectDevelopmentStream {
+		pds := mkStream("my-stream", "my-project", ready)
+		pds.Generation = 1
+		pds.Status.Conditions[0].ObservedGeneration = 1
+		pds.Status.TemplateRevision = revision
+		return &pds
+	}
+
+	DescribeTable(
+		"picks the streams that may be waiting for a rollout",
+		func(pds *projctlv1beta1.ProjectDevelopmentStream, expected bool) {
+			Expect(mayWaitForRollout(pds, "my-template-2")).To(Equal(expected))
+		},
+		Entry("stream held back", mkRolloutStream("my-template-1", metav1.ConditionUnknown), true),
+		Entry("ready stream on another revision", mkRolloutStream("my-template-1", metav1.ConditionTrue), true),
+		Entry("ready stream on the same revision", mkRolloutStream("my-template-2", metav1.ConditionTrue), false),
+		Entry("stream that never applied the template", mkRolloutStream("", metav1.ConditionUnknown), false),
+		Entry("stream whose spec changed", func() *projctlv1beta1.ProjectDevelopmentStream {
+			pds := mkRolloutStream("my-template-1", metav1.ConditionUnknown)
+			pds.Generation = 2
+			return pds
+		}(), false),
+		Entry("pinned stream", func() *projctlv1beta1.ProjectDevelopmentStream {
+			pds := mkRolloutStream("my-template-1", metav1.ConditionUnknown)
+			pds.Spec.Template = &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: "my-template", Revision: "1"}
+			return pds
+		}(), false),
+	)
+})
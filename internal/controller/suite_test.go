@@ -58,6 +58,7 @@ var cfg *rest.Config
 var k8sClient client.Client
 var saClient client.Client
 var saCluster cluster.Cluster
+var stopSaCluster context.CancelFunc
 var testEnv *envtest.Environment
 var applicationAPICrdTempDir string
 
@@ -167,10 +168,21 @@ var _ = BeforeSuite(func() {
 	saCluster, err = cluster.New(saCfg)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(saCluster).NotTo(BeNil())
+	Expect(SetupFieldIndexes(ctx, saCluster.GetFieldIndexer())).To(Succeed())
+
+	var clusterCtx context.Context
+	clusterCtx, stopSaCluster = context.WithCancel(ctx)
+	go func() {
+		defer GinkgoRecover()
+		Expect(saCluster.Start(clusterCtx)).To(Succeed())
+	}()
 })
 
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
+	if stopSaCluster != nil {
+		stopSaCluster()
+	}
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
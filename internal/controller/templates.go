@@ -0,0 +1,220 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+	"github.com/konflux-ci/project-controller/pkg/logr/eventr"
+)
+
+// Validate the given namespaced or cluster template, record a revision of its
+// current spec if it is valid, prune old revisions, and update its status with the
+// validation results and the streams that use it. Errors are logged to the
+// logger in the context.
+func reconcileTemplate(ctx context.Context, c client.Client, scheme *runtime.Scheme, tmpl client.Object) error {
+	logger := log.FromContext(ctx)
+
+	kind, pdst := templateOf(tmpl)
+	var opts []client.ListOption
+	if kind == projctlv1beta1.TemplateKindNamespaced {
+		opts = append(opts, client.InNamespace(pdst.GetNamespace()))
+	}
+	var pdsList projctlv1beta1.ProjectDevelopmentStreamList
+	if err := c.List(ctx, &pdsList, opts...); err != nil {
+		logger.Error(err, "Failed listing dev streams")
+		return err
+	}
+
+	resolved, condition := validCondition(ctx, c, pdst)
+	// Streams cannot be pinned to revisions of invalid templates, so there is
+	// no point in recording them
+	if condition.Status == metav1.ConditionTrue {
+		revision, revisionList := newTemplateRevision(kind, resolved)
+		id := template.RevisionID(resolved)
+		if err := createTemplateRevision(ctx, c, scheme, tmpl, id, revision); err != nil {
+			logger.Error(err, "Failed to create template revision")
+			return err
+		}
+		if err := pruneTemplateRevisions(
+			ctx, c, tmpl, pdst.Spec.RevisionHistoryLimit, template.RevisionName(pdst.Name, id),
+			pinnedRevisions(pdsList.Items, kind, pdst.Name), revisionList,
+		); err != nil {
+			logger.Error(err, "Failed to prune template revisions")
+			return err
+		}
+	}
+	// Streams using a cluster template are listed along with their namespace
+	var streams []string
+	for _, pds := range pdsList.Items {
+		if !template.UsesTemplate(&pds, kind, pdst.Name) {
+			continue
+		}
+		if kind == projctlv1beta1.TemplateKindCluster {
+			streams = append(streams, client.ObjectKeyFromObject(&pds).String())
+		} else {
+			streams = append(streams, pds.Name)
+		}
+	}
+	slices.Sort(streams)
+
+	return applyTemplateStatus(ctx, c, tmpl, condition, streams, resolved)
+}
+
+// Returns the kind of the given namespaced or cluster template, along with the
+// template itself, or its cluster template converted into a template with no
+// namespace
+func templateOf(tmpl client.Object) (projctlv1beta1.TemplateKind, *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+	if cpdst, ok := tmpl.(*projctlv1beta1.ClusterProjectDevelopmentStreamTemplate); ok {
+		return projctlv1beta1.TemplateKindCluster, &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			ObjectMeta: cpdst.ObjectMeta,
+			Spec:       cpdst.Spec,
+			Status:     cpdst.Status,
+		}
+	}
+	return projctlv1beta1.TemplateKindNamespaced, tmpl.(*projctlv1beta1.ProjectDevelopmentStreamTemplate)
+}
+
+// Returns a revision of the given kind of template recording the given merged
+// template, along with an empty list of such revisions
+func newTemplateRevision(
+	kind projctlv1beta1.TemplateKind,
+	resolved *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (client.Object, client.ObjectList) {
+	if kind == projctlv1beta1.TemplateKindCluster {
+		return &projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision{
+			Revision: resolved.Generation,
+			Bases:    resolved.Status.Bases,
+			Spec:     resolved.Spec,
+		}, &projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevisionList{}
+	}
+	return &projctlv1beta1.ProjectDevelopmentStreamTemplateRevision{
+		Revision: resolved.Generation,
+		Bases:    resolved.Status.Bases,
+		Spec:     resolved.Spec,
+	}, &projctlv1beta1.ProjectDevelopmentStreamTemplateRevisionList{}
+}
+
+// Validate the given template, with the templates it extends merged into it,
+// and return a Valid condition describing the result, along with the merged
+// template, which is nil if the templates cannot be merged. Cluster templates
+// are expected to be given converted into templates with no namespace. Issues
+// found are also logged to the logger in the context.
+func validCondition(
+	ctx context.Context,
+	c client.Reader,
+	pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, metav1.Condition) {
+	logger := log.FromContext(ctx)
+
+	condition := metav1.Condition{
+		Type:    ConditionTypeValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "TemplateValid",
+		Message: "Template is valid",
+	}
+	resolved, err := template.Resolve(ctx, c, pdst)
+	if err != nil {
+		logger.Error(err, "Failed to merge extended templates", eventr.ReasonLogKey, "ExtendsFailed")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ExtendsFailed"
+		condition.Message = err.Error()
+		return nil, condition
+	}
+	if err := template.Validate(*resolved); err != nil {
+		logger.Error(err, "Template is invalid", eventr.ReasonLogKey, "TemplateInvalid")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "TemplateInvalid"
+		condition.Message = err.Error()
+	}
+	return resolved, condition
+}
+
+// Set the given condition and stream list, and the extended templates of the
+// given merged template, if any, on the status of the given namespaced or
+// cluster template using server-side apply
+func applyTemplateStatus(
+	ctx context.Context,
+	c client.Client,
+	tmpl client.Object,
+	condition metav1.Condition,
+	streams []string,
+	resolved *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) error {
+	logger := log.FromContext(ctx)
+
+	kind, pdst := templateOf(tmpl)
+	condition.ObservedGeneration = pdst.Generation
+	condition.LastTransitionTime = metav1.Now()
+	// Preserve LastTransitionTime when status hasn't changed per Kubernetes API conventions.
+	existing := meta.FindStatusCondition(pdst.Status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+
+	gvk, err := c.GroupVersionKindFor(tmpl)
+	if err != nil {
+		logger.Error(err, "Failed to get GVK for template", "kind", kind)
+		return err
+	}
+	status := projctlv1beta1.ProjectDevelopmentStreamTemplateStatus{
+		Conditions:         []metav1.Condition{condition},
+		DevelopmentStreams: streams,
+		Bases:              resolvedBases(resolved),
+	}
+	var applyStatus client.Object = &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: pdst.Namespace, Name: pdst.Name},
+		Status:     status,
+	}
+	if kind == projctlv1beta1.TemplateKindCluster {
+		applyStatus = &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: pdst.Name},
+			Status:     status,
+		}
+	}
+	applyStatus.GetObjectKind().SetGroupVersionKind(gvk)
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(applyStatus)
+	if err != nil {
+		logger.Error(err, "Failed to convert status to unstructured")
+		return err
+	}
+	applyObj := &unstructured.Unstructured{Object: u}
+	if err := c.Status().Apply(ctx, client.ApplyConfigurationFromUnstructured(applyObj), client.FieldOwner(FieldManager)); err != nil {
+		logger.Error(err, "Failed to update template status", "kind", kind)
+		return err
+	}
+	return nil
+}
+
+// Returns the extended templates of the given merged template, or nil if
+// there is no merged template
+func resolvedBases(resolved *projctlv1beta1.ProjectDevelopmentStreamTemplate) []projctlv1beta1.TemplateBase {
+	if resolved == nil {
+		return nil
+	}
+	return resolved.Status.Bases
+}
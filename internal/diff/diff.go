@@ -0,0 +1,104 @@
+// Package diff describes the differences between two versions of an object in
+// a human-readable way
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Field names that can be used as-is in a field path
+var plainFieldName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Objects returns the changes needed to turn the old version of an unstructured
+// object into the new one, one line per changed field, sorted by field path.
+// Lines start with "+" for added fields, "-" for removed fields and "~" for
+// modified fields, followed by the field path and its values.
+func Objects(oldObj, newObj map[string]any) []string {
+	var changes []string
+	compare("", oldObj, newObj, &changes)
+	return changes
+}
+
+func compare(path string, oldVal, newVal any, changes *[]string) {
+	switch oldVal := oldVal.(type) {
+	case map[string]any:
+		if newVal, ok := newVal.(map[string]any); ok {
+			compareMaps(path, oldVal, newVal, changes)
+			return
+		}
+	case []any:
+		if newVal, ok := newVal.([]any); ok {
+			compareLists(path, oldVal, newVal, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*changes = append(*changes, fmt.Sprintf("~ %s: %s -> %s", path, format(oldVal), format(newVal)))
+	}
+}
+
+func compareMaps(path string, oldMap, newMap map[string]any, changes *[]string) {
+	keys := make([]string, 0, len(oldMap)+len(newMap))
+	for key := range oldMap {
+		keys = append(keys, key)
+	}
+	for key := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		keyPath := fieldPath(path, key)
+		oldVal, inOld := oldMap[key]
+		newVal, inNew := newMap[key]
+		switch {
+		case !inOld:
+			*changes = append(*changes, fmt.Sprintf("+ %s: %s", keyPath, format(newVal)))
+		case !inNew:
+			*changes = append(*changes, fmt.Sprintf("- %s: %s", keyPath, format(oldVal)))
+		default:
+			compare(keyPath, oldVal, newVal, changes)
+		}
+	}
+}
+
+func compareLists(path string, oldList, newList []any, changes *[]string) {
+	for i := range max(len(oldList), len(newList)) {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(oldList):
+			*changes = append(*changes, fmt.Sprintf("+ %s: %s", itemPath, format(newList[i])))
+		case i >= len(newList):
+			*changes = append(*changes, fmt.Sprintf("- %s: %s", itemPath, format(oldList[i])))
+		default:
+			compare(itemPath, oldList[i], newList[i], changes)
+		}
+	}
+}
+
+// Returns the path of the given field of the object found in the given path.
+// Field names that are not plain words (e.g. annotation names) are quoted.
+func fieldPath(path, field string) string {
+	if !plainFieldName.MatchString(field) {
+		return fmt.Sprintf("%s[%q]", path, field)
+	}
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// Format a field value for display
+func format(value any) string {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,46 @@
+package diff_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/project-controller/internal/diff"
+)
+
+var _ = DescribeTable(
+	"Objects describes the changes between objects",
+	func(oldObj, newObj map[string]any, expected []string) {
+		Expect(diff.Objects(oldObj, newObj)).To(Equal(expected))
+	},
+	Entry("identical objects", map[string]any{"a": "b"}, map[string]any{"a": "b"}, nil),
+	Entry(
+		"modified fields",
+		map[string]any{"spec": map[string]any{"name": "old", "count": int64(1)}},
+		map[string]any{"spec": map[string]any{"name": "new", "count": int64(2)}},
+		[]string{`~ spec.count: 1 -> 2`, `~ spec.name: "old" -> "new"`},
+	),
+	Entry(
+		"added and removed fields",
+		map[string]any{"spec": map[string]any{"old": "value"}},
+		map[string]any{"spec": map[string]any{"new": map[string]any{"a": "b"}}},
+		[]string{`+ spec.new: {"a":"b"}`, `- spec.old: "value"`},
+	),
+	Entry(
+		"fields with names that need quoting",
+		map[string]any{"metadata": map[string]any{"annotations": map[string]any{"example.com/a": "1"}}},
+		map[string]any{"metadata": map[string]any{"annotations": map[string]any{"example.com/a": "2"}}},
+		[]string{`~ metadata.annotations["example.com/a"]: "1" -> "2"`},
+	),
+	Entry(
+		"list items",
+		map[string]any{"items": []any{"a", map[string]any{"v": "b"}, "c"}},
+		map[string]any{"items": []any{"a", map[string]any{"v": "x"}}},
+		[]string{`~ items[1].v: "b" -> "x"`, `- items[2]: "c"`},
+	),
+	Entry(
+		"fields that changed type",
+		map[string]any{"spec": map[string]any{"a": "b"}},
+		map[string]any{"spec": "b"},
+		[]string{`~ spec: {"a":"b"} -> "b"`},
+	),
+)
@@ -1,26 +1,193 @@
 package template
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
 )
 
 var nameFieldInvalidCharPattern = regexp.MustCompile("[^a-z0-9]")
+
+// Functions available to templates. They must not depend on anything but their
+// arguments (e.g. the environment or the clock), so that rendering a template
+// with the same values always gives the same result. The value being worked
+// on is the last argument, so functions can be used in pipelines.
 var templateFuncs = template.FuncMap{
 	"hyphenize": func(str string) string {
 		return nameFieldInvalidCharPattern.ReplaceAllString(str, "-")
 	},
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trunc":      trunc,
+	"trimPrefix": func(prefix, str string) string { return strings.TrimPrefix(str, prefix) },
+	"trimSuffix": func(suffix, str string) string { return strings.TrimSuffix(str, suffix) },
+	"replace": func(old, replacement, str string) string {
+		return strings.ReplaceAll(str, old, replacement)
+	},
+	"regexReplace": func(pattern, replacement, str string) (string, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		return re.ReplaceAllString(str, replacement), nil
+	},
+	"semverMajor": func(version string) (int, error) { return semverPart(version, 1) },
+	"semverMinor": func(version string) (int, error) { return semverPart(version, 2) },
+	"semverPatch": func(version string) (int, error) { return semverPart(version, 3) },
+	"sha256sum":   sha256sum,
+	"shortHash": func(n int, str string) (string, error) {
+		if n < 1 || n > sha256.Size*2 {
+			return "", fmt.Errorf("hash length must be between 1 and %d, got %d", sha256.Size*2, n)
+		}
+		return sha256sum(str)[:n], nil
+	},
+	"default": func(defaultValue, value any) any {
+		if isEmpty(value) {
+			return defaultValue
+		}
+		return value
+	},
+	"required": func(message string, value any) (any, error) {
+		if isEmpty(value) {
+			return nil, errors.New(message)
+		}
+		return value, nil
+	},
+	"join": join,
+	"split": func(sep, str string) []string {
+		if str == "" {
+			return nil
+		}
+		return strings.Split(str, sep)
+	},
+	"toJson": func(value any) (string, error) {
+		out, err := json.Marshal(value)
+		return string(out), err
+	},
+}
+
+// Returns the first n characters of the given string, or the last -n
+// characters if n is negative
+func trunc(n int, str string) string {
+	runes := []rune(str)
+	switch {
+	case n >= 0 && n < len(runes):
+		return string(runes[:n])
+	case n < 0 && -n < len(runes):
+		return string(runes[len(runes)+n:])
+	}
+	return str
+}
+
+// Joins the given items, which may be the items of list or yaml variables as
+// well as the output of split, with the given separator
+func join(sep string, items any) (string, error) {
+	switch items := items.(type) {
+	case nil:
+		return "", nil
+	case []string:
+		return strings.Join(items, sep), nil
+	case []any:
+		strs := make([]string, len(items))
+		for i, item := range items {
+			strs[i] = fmt.Sprint(item)
+		}
+		return strings.Join(strs, sep), nil
+	}
+	return "", fmt.Errorf("cannot join %T, expected a list", items)
+}
+
+// Returns the hex-encoded SHA-256 hash of the given string
+func sha256sum(str string) string {
+	sum := sha256.Sum256([]byte(str))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns the given part (1 for major, 2 for minor and 3 for patch) of the
+// given semantic version
+func semverPart(version string, part int) (int, error) {
+	match := semverPattern.FindStringSubmatch(version)
+	if match == nil {
+		return 0, fmt.Errorf("'%s' is not a semantic version (e.g. 1.2.3)", version)
+	}
+	return strconv.Atoi(match[part])
+}
+
+// Check whether the given template value is missing or an empty string
+func isEmpty(value any) bool {
+	str, ok := value.(string)
+	return value == nil || ok && str == ""
+}
+
+// Names of the built-in context entries templates can reference in addition to
+// the template variables. A template variable with the same name takes
+// precedence over a built-in entry.
+const (
+	streamContextKey  = "stream"
+	projectContextKey = "project"
+)
+
+var builtinContextKeys = []string{streamContextKey, projectContextKey}
+
+// Returns the read-only context made available to templates generating
+// resources for the given stream. The project may be nil if it does not exist,
+// in which case only its name is known.
+func builtinContext(pds *projctlv1beta1.ProjectDevelopmentStream, project *projctlv1beta1.Project) map[string]any {
+	projectCtx := map[string]any{
+		"name":        pds.Spec.Project,
+		"displayName": "",
+		"description": "",
+	}
+	if project != nil {
+		projectCtx["displayName"] = project.Spec.DisplayName
+		projectCtx["description"] = project.Spec.Description
+	}
+	return map[string]any{
+		streamContextKey: map[string]any{
+			"name":        pds.GetName(),
+			"namespace":   pds.GetNamespace(),
+			"labels":      maps.Clone(pds.GetLabels()),
+			"annotations": maps.Clone(pds.GetAnnotations()),
+		},
+		projectContextKey: projectCtx,
+	}
+}
+
+// Returns the data templates are executed with: the given built-in context
+// with the given template variable values added on top of it
+func templateData(builtins, values map[string]any) map[string]any {
+	data := maps.Clone(builtins)
+	if data == nil {
+		data = make(map[string]any, len(values))
+	}
+	for name, value := range values {
+		data[name] = value
+	}
+	return data
+}
+
+// Parse the template given as a string
+func parseTemplate(templateStr string) (*template.Template, error) {
+	return template.New("").Funcs(templateFuncs).Parse(templateStr)
 }
 
 // Execute the template given as a string and return the result as a string
-func executeTemplate(templateStr string, values map[string]string) (string, error) {
-	theTemplate, err := template.New("").Funcs(templateFuncs).Parse(templateStr)
+func executeTemplate(templateStr string, data map[string]any) (string, error) {
+	theTemplate, err := parseTemplate(templateStr)
 	if err != nil {
 		return "", err
 	}
 	var valueBuf strings.Builder
-	if err := theTemplate.Execute(&valueBuf, values); err != nil {
+	if err := theTemplate.Execute(&valueBuf, data); err != nil {
 		return "", err
 	}
 	return valueBuf.String(), nil
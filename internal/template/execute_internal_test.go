@@ -7,7 +7,7 @@ import (
 
 var _ = DescribeTable(
 	"Execute applies a template string",
-	func(template string, values map[string]string, expected string) {
+	func(template string, values map[string]any, expected string) {
 		out, err := executeTemplate(template, values)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(out).To(Equal(expected))
@@ -15,19 +15,136 @@ var _ = DescribeTable(
 	Entry(
 		"for simple template and value",
 		"{{.version}}",
-		map[string]string{"version": "1.2.3"},
+		map[string]any{"version": "1.2.3"},
 		"1.2.3",
 	),
 	Entry(
 		"and suports the hyphenize function",
 		"{{.version|hyphenize}}",
-		map[string]string{"version": "1.2.3"},
+		map[string]any{"version": "1.2.3"},
 		"1-2-3",
 	),
+	Entry(
+		"and supports changing case",
+		"{{.name|lower}}-{{.name|upper}}",
+		map[string]any{"name": "Konflux"},
+		"konflux-KONFLUX",
+	),
+	Entry(
+		"and supports truncating",
+		"{{.name|trunc 4}}-{{.name|trunc -3}}-{{.name|trunc 20}}",
+		map[string]any{"name": "project"},
+		"proj-ect-project",
+	),
+	Entry(
+		"and supports trimming prefixes and suffixes",
+		`{{.version|trimPrefix "v"}} {{.image|trimSuffix ":latest"}}`,
+		map[string]any{"version": "v1.2.3", "image": "quay.io/org/img:latest"},
+		"1.2.3 quay.io/org/img",
+	),
+	Entry(
+		"and supports replacing strings",
+		`{{.version|replace "." "_"}} {{.branch|regexReplace "^release-(.+)$" "rel-$1"}}`,
+		map[string]any{"version": "1.2.3", "branch": "release-4.22"},
+		"1_2_3 rel-4.22",
+	),
+	Entry(
+		"and supports getting semantic version parts",
+		"{{semverMajor .version}}.{{semverMinor .version}}.{{semverPatch .version}}"+
+			"{{if ge (semverMajor .version) 2}} new{{end}}",
+		map[string]any{"version": "2.10.3-rc.1"},
+		"2.10.3 new",
+	),
+	Entry(
+		"and supports hashing",
+		"{{.name|sha256sum|trunc 8}}",
+		map[string]any{"name": "project"},
+		"244210e4",
+	),
+	Entry(
+		"and supports short hashes",
+		"{{.name|shortHash 8}} {{.name|shortHash 64|len}}",
+		map[string]any{"name": "project"},
+		"244210e4 64",
+	),
+	Entry(
+		"and supports defaults",
+		`{{.missing|default "none"}} {{.empty|default "none"}} {{.name|default "none"}}`,
+		map[string]any{"empty": "", "name": "project"},
+		"none none project",
+	),
+	Entry(
+		"and supports required values",
+		`{{required "name is needed" .name}}`,
+		map[string]any{"name": "project"},
+		"project",
+	),
+	Entry(
+		"and supports splitting and joining",
+		`{{.archs|split ","|join "-"}} {{len (split "," .none)}}`,
+		map[string]any{"archs": "amd64,arm64", "none": ""},
+		"amd64-arm64 0",
+	),
+	Entry(
+		"and supports joining the items of list and yaml variables",
+		`{{join ", " .archs}} {{join "+" .numbers}} [{{join ", " .none}}]`,
+		map[string]any{"archs": []any{"amd64", "arm64"}, "numbers": []any{int64(1), 2.5}, "none": nil},
+		"amd64, arm64 1+2.5 []",
+	),
+	Entry(
+		"and supports converting values to JSON",
+		`{{toJson .params}} {{toJson .name}}`,
+		map[string]any{"params": []any{map[string]any{"name": "a", "value": int64(1)}}, "name": "project"},
+		`[{"name":"a","value":1}] "project"`,
+	),
 	Entry(
 		"with newline inside delimiters (as produced by YAML line wrapping after parsing)",
 		"quay.io/tenant/comp-{{\n      .versionName }}:tag",
-		map[string]string{"versionName": "4-22"},
+		map[string]any{"versionName": "4-22"},
 		"quay.io/tenant/comp-4-22:tag",
 	),
 )
+
+var _ = DescribeTable(
+	"Execute reports function errors",
+	func(template string, values map[string]any, expected string) {
+		_, err := executeTemplate(template, values)
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+	Entry(
+		"for versions that are not semantic versions",
+		"{{semverMajor .version}}",
+		map[string]any{"version": "v1.2"},
+		"'v1.2' is not a semantic version (e.g. 1.2.3)",
+	),
+	Entry(
+		"for invalid regular expressions",
+		`{{.name|regexReplace "(" ""}}`,
+		map[string]any{"name": "project"},
+		"error parsing regexp",
+	),
+	Entry(
+		"for joining values that are not lists",
+		`{{join ", " .name}}`,
+		map[string]any{"name": "project"},
+		"cannot join string, expected a list",
+	),
+	Entry(
+		"for missing required values",
+		`{{required "name is needed" .name}}`,
+		map[string]any{},
+		"name is needed",
+	),
+	Entry(
+		"for short hashes that are too short",
+		"{{.name|shortHash 0}}",
+		map[string]any{"name": "project"},
+		"hash length must be between 1 and 64, got 0",
+	),
+	Entry(
+		"for short hashes that are longer than the hash",
+		"{{.name|shortHash 65}}",
+		map[string]any{"name": "project"},
+		"hash length must be between 1 and 64, got 65",
+	),
+)
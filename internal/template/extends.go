@@ -0,0 +1,306 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// Issues reported by Resolve that prevent merging a template with the
+// templates it extends, until the templates are changed
+var (
+	ErrExtendsCycle      = errors.New("templates extend each other in a cycle")
+	ErrExtendsConflict   = errors.New("conflicting definitions in extended templates")
+	ErrExtendsNamespaced = errors.New("cluster templates can only extend other cluster templates")
+)
+
+// IsExtendsError returns true if the given error reports one of the issues
+// with the way templates extend each other that Resolve detects
+func IsExtendsError(err error) bool {
+	return errors.Is(err, ErrExtendsCycle) || errors.Is(err, ErrExtendsConflict) || errors.Is(err, ErrExtendsNamespaced)
+}
+
+// BaseKind returns the kind of template the given reference to an extended
+// template points to, taking the default into account
+func BaseKind(ref projctlv1beta1.TemplateReference) projctlv1beta1.TemplateKind {
+	if ref.Kind == "" {
+		return projctlv1beta1.TemplateKindNamespaced
+	}
+	return ref.Kind
+}
+
+// ID identifies a namespaced or a cluster template, the latter having no
+// namespace
+type ID struct {
+	Kind      projctlv1beta1.TemplateKind
+	Namespace string
+	Name      string
+}
+
+// Namespaces are left out, as namespaced templates can only extend templates
+// in their own namespace
+func (id ID) String() string {
+	return fmt.Sprintf("%s '%s'", id.Kind, id.Name)
+}
+
+// Returns the template with the given ID, or an error satisfying
+// apierrors.IsNotFound if it does not exist
+type templateFetcher func(id ID) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error)
+
+// Resolve returns a copy of the given template with the templates it extends,
+// directly or via other templates, merged into its spec. As with Get, cluster
+// templates are expected to be given converted into templates with no
+// namespace. The templates are merged in the order they are extended in,
+// followed by the given template itself:
+//   - Variables are collected from all templates. Templates defining the same
+//     variable differently are reported as a conflict.
+//   - Resources are collected from all templates, with a resource replacing
+//     any resource of an earlier template that has the same kind and
+//     (unrendered) name.
+//   - Templated fields are collected from all templates. Templates giving the
+//     same field different types, or setting different templating modes, are
+//     reported as a conflict.
+//   - The project and rollout strategy of the given template are used.
+//
+// The extended templates are listed, with their current generations, in the
+// status of the returned template (see RevisionID). Templates that extend each
+// other in a cycle are reported as well.
+func Resolve(
+	ctx context.Context,
+	c client.Reader,
+	pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+	return resolve(pdst, ownID(pdst), func(id ID) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+		if id.Kind == projctlv1beta1.TemplateKindCluster {
+			var cpdst projctlv1beta1.ClusterProjectDevelopmentStreamTemplate
+			if err := c.Get(ctx, client.ObjectKey{Name: id.Name}, &cpdst); err != nil {
+				return nil, err
+			}
+			return &projctlv1beta1.ProjectDevelopmentStreamTemplate{ObjectMeta: cpdst.ObjectMeta, Spec: cpdst.Spec}, nil
+		}
+		var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+		if err := c.Get(ctx, client.ObjectKey{Namespace: id.Namespace, Name: id.Name}, &pdst); err != nil {
+			return nil, err
+		}
+		return &pdst, nil
+	})
+}
+
+// ResolveFromList works like Resolve, but looks up the extended templates in
+// the given list rather than in the cluster. Templates are told apart by their
+// kind and name, so the templates are expected to have their kind set, and
+// namespaces are ignored.
+func ResolveFromList(
+	pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+	templates []projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+	kindOf := func(t *projctlv1beta1.ProjectDevelopmentStreamTemplate) projctlv1beta1.TemplateKind {
+		if t.Kind == string(projctlv1beta1.TemplateKindCluster) {
+			return projctlv1beta1.TemplateKindCluster
+		}
+		return projctlv1beta1.TemplateKindNamespaced
+	}
+	return resolve(
+		pdst,
+		ID{Kind: kindOf(pdst), Namespace: pdst.GetNamespace(), Name: pdst.GetName()},
+		func(id ID) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+			for i := range templates {
+				if kindOf(&templates[i]) == id.Kind && templates[i].GetName() == id.Name {
+					return &templates[i], nil
+				}
+			}
+			gr := projctlv1beta1.GroupVersion.WithResource(strings.ToLower(string(id.Kind)) + "s").GroupResource()
+			return nil, apierrors.NewNotFound(gr, id.Name)
+		},
+	)
+}
+
+// Returns the ID of the given template, which is a cluster template if it has
+// no namespace
+func ownID(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) ID {
+	if pdst.GetNamespace() == "" {
+		return ID{Kind: projctlv1beta1.TemplateKindCluster, Name: pdst.GetName()}
+	}
+	return ID{Kind: projctlv1beta1.TemplateKindNamespaced, Namespace: pdst.GetNamespace(), Name: pdst.GetName()}
+}
+
+// Returns the ID of the template the given reference in the template with the
+// given ID points to
+func baseID(id ID, ref projctlv1beta1.TemplateReference) (ID, error) {
+	kind := BaseKind(ref)
+	if kind == projctlv1beta1.TemplateKindCluster {
+		return ID{Kind: kind, Name: ref.Name}, nil
+	}
+	if id.Kind == projctlv1beta1.TemplateKindCluster {
+		return ID{}, fmt.Errorf("%w: %s cannot extend %s '%s'", ErrExtendsNamespaced, id, kind, ref.Name)
+	}
+	return ID{Kind: kind, Namespace: id.Namespace, Name: ref.Name}, nil
+}
+
+func resolve(
+	pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate, id ID, fetch templateFetcher,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+	if len(pdst.Spec.Extends) == 0 {
+		if len(pdst.Status.Bases) == 0 {
+			return pdst, nil
+		}
+		// The status may list the templates the template extended before
+		resolved := pdst.DeepCopy()
+		resolved.Status.Bases = nil
+		return resolved, nil
+	}
+	m := &merger{
+		fetch:           fetch,
+		varOrigins:      map[string]ID{},
+		resourceOrigins: map[string]ID{},
+		merged:          map[ID]bool{},
+	}
+	if err := m.merge(id, pdst.Spec, nil); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(m.errs...); err != nil {
+		return nil, err
+	}
+	resolved := pdst.DeepCopy()
+	resolved.Spec.Extends = nil
+	resolved.Spec.Variables = m.spec.Variables
+	resolved.Spec.Resources = m.spec.Resources
+	resolved.Spec.TemplatingMode = m.spec.TemplatingMode
+	resolved.Spec.TemplatedFields = m.spec.TemplatedFields
+	resolved.Status.Bases = m.bases
+	return resolved, nil
+}
+
+// Collects the contents of a template and the templates it extends into a
+// single spec
+type merger struct {
+	fetch templateFetcher
+	spec  projctlv1beta1.ProjectDevelopmentStreamTemplateSpec
+	// Which template each variable, resource and the templating mode were
+	// taken from, for reporting conflicts
+	varOrigins      map[string]ID
+	resourceOrigins map[string]ID
+	modeOrigin      ID
+	// Which template each templated field was taken from, by index
+	fieldOrigins []ID
+	// Templates merged so far, so that templates extended via several others
+	// are only merged once
+	merged map[ID]bool
+	// The extended templates merged so far, in order
+	bases []projctlv1beta1.TemplateBase
+	// Conflicts found so far
+	errs []error
+}
+
+// Merge the given spec of the template with the given ID, after the templates
+// it extends. The chain of templates that led to it is given for detecting
+// cycles. Missing templates and cycles end the merge while conflicts are
+// collected.
+func (m *merger) merge(id ID, spec projctlv1beta1.ProjectDevelopmentStreamTemplateSpec, chain []ID) error {
+	chain = append(chain, id)
+	for _, ref := range spec.Extends {
+		base, err := baseID(id, ref)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(chain, base) {
+			names := make([]string, 0, len(chain)+1)
+			for _, link := range append(chain, base) {
+				names = append(names, link.String())
+			}
+			return fmt.Errorf("%w: %s", ErrExtendsCycle, strings.Join(names, " -> "))
+		}
+		if m.merged[base] {
+			continue
+		}
+		basePdst, err := m.fetch(base)
+		if err != nil {
+			return fmt.Errorf("failed to fetch template %s extended by %s: %w", base, id, err)
+		}
+		if err := m.merge(base, basePdst.Spec, chain); err != nil {
+			return err
+		}
+		m.bases = append(m.bases, projctlv1beta1.TemplateBase{
+			Kind: base.Kind, Name: base.Name, Generation: basePdst.GetGeneration(),
+		})
+	}
+
+	for _, variable := range spec.Variables {
+		origin, ok := m.varOrigins[variable.Name]
+		if ok && origin != id {
+			idx := slices.IndexFunc(m.spec.Variables, func(v projctlv1beta1.ProjectDevelopmentStreamTemplateVariable) bool {
+				return v.Name == variable.Name
+			})
+			if !equality.Semantic.DeepEqual(m.spec.Variables[idx], variable) {
+				m.errs = append(m.errs, fmt.Errorf(
+					"%w: template variable '%s' is defined differently by %s and %s",
+					ErrExtendsConflict, variable.Name, origin, id,
+				))
+			}
+			continue
+		}
+		m.varOrigins[variable.Name] = id
+		m.spec.Variables = append(m.spec.Variables, variable)
+	}
+
+	for _, resource := range spec.Resources {
+		key := resourceKey(&resource)
+		if origin, ok := m.resourceOrigins[key]; ok && origin != id {
+			idx := slices.IndexFunc(m.spec.Resources, func(r projctlv1beta1.UnstructuredObj) bool {
+				return resourceKey(&r) == key
+			})
+			m.spec.Resources[idx] = resource
+			m.resourceOrigins[key] = id
+			continue
+		}
+		m.resourceOrigins[key] = id
+		m.spec.Resources = append(m.spec.Resources, resource)
+	}
+
+	if spec.TemplatingMode != "" {
+		if m.spec.TemplatingMode != "" && m.spec.TemplatingMode != spec.TemplatingMode {
+			m.errs = append(m.errs, fmt.Errorf(
+				"%w: templating mode is set to %s by %s and to %s by %s",
+				ErrExtendsConflict, m.spec.TemplatingMode, m.modeOrigin, spec.TemplatingMode, id,
+			))
+		} else {
+			m.spec.TemplatingMode = spec.TemplatingMode
+			m.modeOrigin = id
+		}
+	}
+	for _, field := range spec.TemplatedFields {
+		idx := slices.IndexFunc(m.spec.TemplatedFields, func(f projctlv1beta1.TemplatedField) bool {
+			return f.Kind == field.Kind && slices.Equal(f.Path, field.Path)
+		})
+		if idx == -1 {
+			m.fieldOrigins = append(m.fieldOrigins, id)
+			m.spec.TemplatedFields = append(m.spec.TemplatedFields, field)
+			continue
+		}
+		if existing := m.spec.TemplatedFields[idx]; fieldType(existing) != fieldType(field) {
+			m.errs = append(m.errs, fmt.Errorf(
+				"%w: templated field '%s' of %s resources has type %s in %s and %s in %s",
+				ErrExtendsConflict, strings.Join(field.Path, "."), field.Kind,
+				fieldType(existing), m.fieldOrigins[idx], fieldType(field), id,
+			))
+		}
+	}
+	m.merged[id] = true
+	return nil
+}
+
+// Returns the key by which resources of different templates replace each
+// other, made of the resource kind and unrendered name
+func resourceKey(resource *projctlv1beta1.UnstructuredObj) string {
+	gk := schema.FromAPIVersionAndKind(resource.GetAPIVersion(), resource.GetKind()).GroupKind()
+	return gk.String() + "/" + resource.GetName()
+}
@@ -0,0 +1,235 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("Resolve", func() {
+	// Make a template of the given kind extending the given templates
+	mkTemplate := func(
+		kind projctlv1beta1.TemplateKind, name string, extends ...projctlv1beta1.TemplateReference,
+	) projctlv1beta1.ProjectDevelopmentStreamTemplate {
+		return projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			TypeMeta:   metav1.TypeMeta{Kind: string(kind)},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{Extends: extends},
+		}
+	}
+
+	ref := func(name string) projctlv1beta1.TemplateReference {
+		return projctlv1beta1.TemplateReference{Name: name}
+	}
+
+	mkVar := func(name, defaultValue string) projctlv1beta1.ProjectDevelopmentStreamTemplateVariable {
+		return projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Name: name, DefaultValue: &defaultValue}
+	}
+
+	mkResource := func(kind, name, displayName string) projctlv1beta1.UnstructuredObj {
+		return projctlv1beta1.UnstructuredObj{Unstructured: unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "appstudio.redhat.com/v1alpha1",
+			"kind":       kind,
+			"metadata":   map[string]any{"name": name},
+			"spec":       map[string]any{"displayName": displayName},
+		}}}
+	}
+
+	It("returns templates that extend nothing as they are", func() {
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a")
+		pdst.Spec.Variables = []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v", "1")}
+		Expect(ResolveFromList(&pdst, nil)).To(Equal(&pdst))
+	})
+
+	It("merges the extended templates in order", func() {
+		base1 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base1")
+		base1.Spec.Variables = []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v1", "1")}
+		base1.Spec.Resources = []projctlv1beta1.UnstructuredObj{
+			mkResource("Application", "app-{{.v1}}", "Base 1 app"),
+			mkResource("Component", "comp-{{.v1}}", "Base 1 component"),
+		}
+		base1.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{{Kind: "Component", Path: []string{"spec", "x"}}}
+		base2 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base2")
+		base2.Spec.Variables = []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v1", "1"), mkVar("v2", "2")}
+		base2.Spec.Resources = []projctlv1beta1.UnstructuredObj{mkResource("Component", "other-{{.v2}}", "Base 2 component")}
+		base2.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Component", Path: []string{"spec", "x"}},
+			{Kind: "Component", Path: []string{"spec", "y"}},
+		}
+		base2.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllowlist
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("base1"), ref("base2"))
+		pdst.Spec.Project = "my-project"
+		pdst.Spec.Rollout = &projctlv1beta1.RolloutStrategy{Canaries: []string{"s"}}
+		pdst.Spec.Variables = []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v3", "3")}
+		pdst.Spec.Resources = []projctlv1beta1.UnstructuredObj{
+			mkResource("Application", "app-{{.v1}}", "Own app"),
+			mkResource("Application", "own-{{.v3}}", "Own other app"),
+		}
+
+		resolved, err := ResolveFromList(&pdst, []projctlv1beta1.ProjectDevelopmentStreamTemplate{base1, base2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Spec.Extends).To(BeEmpty())
+		Expect(resolved.Spec.Project).To(Equal("my-project"))
+		Expect(resolved.Spec.Rollout).To(Equal(pdst.Spec.Rollout))
+		Expect(resolved.Spec.Variables).To(HaveExactElements(
+			HaveField("Name", "v1"), HaveField("Name", "v2"), HaveField("Name", "v3"),
+		))
+		Expect(resolved.Spec.Resources).To(HaveExactElements(
+			mkResource("Application", "app-{{.v1}}", "Own app"),
+			mkResource("Component", "comp-{{.v1}}", "Base 1 component"),
+			mkResource("Component", "other-{{.v2}}", "Base 2 component"),
+			mkResource("Application", "own-{{.v3}}", "Own other app"),
+		))
+		Expect(resolved.Spec.TemplatedFields).To(HaveLen(2))
+		Expect(resolved.Spec.TemplatingMode).To(Equal(projctlv1beta1.TemplatingModeAllowlist))
+		// The given template is left as it is
+		Expect(pdst.Spec.Extends).To(HaveLen(2))
+		Expect(pdst.Spec.Resources).To(HaveLen(2))
+	})
+
+	It("merges templates extended via several others once", func() {
+		common := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "common")
+		common.Spec.Variables = []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v", "1")}
+		common.Spec.Resources = []projctlv1beta1.UnstructuredObj{mkResource("Application", "app", "Common app")}
+		base1 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base1", ref("common"))
+		base1.Spec.Resources = []projctlv1beta1.UnstructuredObj{mkResource("Application", "app", "Base 1 app")}
+		base2 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base2", ref("common"))
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("base1"), ref("base2"))
+
+		resolved, err := ResolveFromList(&pdst, []projctlv1beta1.ProjectDevelopmentStreamTemplate{common, base1, base2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Spec.Variables).To(HaveLen(1))
+		Expect(resolved.Spec.Resources).To(HaveExactElements(mkResource("Application", "app", "Base 1 app")))
+	})
+
+	It("records the generations of the extended templates", func() {
+		common := mkTemplate(projctlv1beta1.TemplateKindCluster, "common")
+		common.Generation = 5
+		base := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base", ref("common"))
+		base.Generation = 2
+		base.Spec.Extends[0].Kind = projctlv1beta1.TemplateKindCluster
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("base"))
+		pdst.Generation = 3
+
+		resolved, err := ResolveFromList(&pdst, []projctlv1beta1.ProjectDevelopmentStreamTemplate{common, base})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Status.Bases).To(HaveExactElements(
+			projctlv1beta1.TemplateBase{Kind: projctlv1beta1.TemplateKindCluster, Name: "common", Generation: 5},
+			projctlv1beta1.TemplateBase{Kind: projctlv1beta1.TemplateKindNamespaced, Name: "base", Generation: 2},
+		))
+		Expect(RevisionID(resolved)).To(Equal("3.5.2"))
+		Expect(RevisionName(resolved.Name, RevisionID(resolved))).To(Equal("a-3.5.2"))
+
+		// Templates that no longer extend anything lose their recorded bases
+		pdst.Spec.Extends = nil
+		pdst.Status.Bases = resolved.Status.Bases
+		resolved, err = ResolveFromList(&pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Status.Bases).To(BeEmpty())
+		Expect(RevisionID(resolved)).To(Equal("3"))
+	})
+
+	It("reports templates that extend each other in a cycle", func() {
+		base1 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base1", ref("base2"))
+		base2 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base2", ref("a"))
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("base1"))
+
+		_, err := ResolveFromList(&pdst, []projctlv1beta1.ProjectDevelopmentStreamTemplate{base1, base2, pdst})
+		Expect(err).To(MatchError(ErrExtendsCycle))
+		Expect(err).To(MatchError(ContainSubstring(
+			"ProjectDevelopmentStreamTemplate 'a' -> ProjectDevelopmentStreamTemplate 'base1' -> " +
+				"ProjectDevelopmentStreamTemplate 'base2' -> ProjectDevelopmentStreamTemplate 'a'",
+		)))
+		Expect(IsExtendsError(err)).To(BeTrue())
+
+		self := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("a"))
+		_, err = ResolveFromList(&self, nil)
+		Expect(err).To(MatchError(ErrExtendsCycle))
+	})
+
+	It("reports conflicting definitions", func() {
+		base1 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base1")
+		base1.Spec.Variables = []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v", "1")}
+		base1.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllStrings
+		base1.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{{Kind: "Component", Path: []string{"spec", "x"}}}
+		base2 := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base2")
+		base2.Spec.Variables = []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v", "2")}
+		base2.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Component", Path: []string{"spec", "x"}, Type: projctlv1beta1.TemplatedFieldTypeString},
+		}
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("base1"), ref("base2"))
+		pdst.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllowlist
+		pdst.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Component", Path: []string{"spec", "x"}, Type: projctlv1beta1.TemplatedFieldTypeBoolean},
+		}
+
+		_, err := ResolveFromList(&pdst, []projctlv1beta1.ProjectDevelopmentStreamTemplate{base1, base2})
+		Expect(err).To(MatchError(ErrExtendsConflict))
+		Expect(err).To(MatchError(ContainSubstring(
+			"template variable 'v' is defined differently by ProjectDevelopmentStreamTemplate 'base1' " +
+				"and ProjectDevelopmentStreamTemplate 'base2'",
+		)))
+		Expect(err).To(MatchError(ContainSubstring(
+			"templating mode is set to AllStrings by ProjectDevelopmentStreamTemplate 'base1' " +
+				"and to Allowlist by ProjectDevelopmentStreamTemplate 'a'",
+		)))
+		Expect(err).To(MatchError(ContainSubstring(
+			"templated field 'spec.x' of Component resources has type string in ProjectDevelopmentStreamTemplate " +
+				"'base1' and boolean in ProjectDevelopmentStreamTemplate 'a'",
+		)))
+	})
+
+	It("reports missing templates", func() {
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("base1"))
+		_, err := ResolveFromList(&pdst, nil)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(IsExtendsError(err)).To(BeFalse())
+	})
+
+	It("only lets cluster templates extend cluster templates", func() {
+		base := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "base")
+		pdst := mkTemplate(projctlv1beta1.TemplateKindCluster, "a", ref("base"))
+		_, err := ResolveFromList(&pdst, []projctlv1beta1.ProjectDevelopmentStreamTemplate{base})
+		Expect(err).To(MatchError(ErrExtendsNamespaced))
+	})
+
+	It("fetches the extended templates from the cluster", func() {
+		scheme := runtime.NewScheme()
+		Expect(projctlv1beta1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&projctlv1beta1.ProjectDevelopmentStreamTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "base"},
+				Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+					Extends: []projctlv1beta1.TemplateReference{{Name: "common", Kind: projctlv1beta1.TemplateKindCluster}},
+					Resources: []projctlv1beta1.UnstructuredObj{
+						mkResource("Component", "comp", "Base component"),
+					},
+				},
+			},
+			&projctlv1beta1.ProjectDevelopmentStreamTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "other-ns", Name: "base"},
+			},
+			&projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "common"},
+				Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+					Variables: []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{mkVar("v", "1")},
+				},
+			},
+		).Build()
+		pdst := mkTemplate(projctlv1beta1.TemplateKindNamespaced, "a", ref("base"))
+		pdst.Namespace = "my-ns"
+
+		resolved, err := Resolve(context.Background(), c, &pdst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Spec.Variables).To(HaveExactElements(HaveField("Name", "v")))
+		Expect(resolved.Spec.Resources).To(HaveExactElements(mkResource("Component", "comp", "Base component")))
+	})
+})
@@ -0,0 +1,154 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// RefKind returns the kind of template the given template reference points
+// to, taking the default into account
+func RefKind(ref *projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef) projctlv1beta1.TemplateKind {
+	if ref.Kind == "" {
+		return projctlv1beta1.TemplateKindNamespaced
+	}
+	return ref.Kind
+}
+
+// UsesTemplate returns true if the given stream refers to the template with
+// the given kind and name. For namespaced templates, the caller is expected
+// to check that the stream and the template are in the same namespace.
+func UsesTemplate(pds *projctlv1beta1.ProjectDevelopmentStream, kind projctlv1beta1.TemplateKind, name string) bool {
+	return pds.Spec.Template != nil && RefKind(pds.Spec.Template) == kind && pds.Spec.Template.Name == name
+}
+
+// IsPinned returns true if the given template reference points to a specific
+// revision of the template rather than to its current spec
+func IsPinned(ref *projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef) bool {
+	return ref != nil && ref.Revision != "" && ref.Revision != projctlv1beta1.TemplateRevisionLatest
+}
+
+// RevisionID returns the identifier of the revision that would be taken from
+// the given template, as returned by Get or Resolve. It is made of the
+// generation of the template followed by the generations of the templates it
+// extends, if any, e.g. "3" or "3.5.2", so it changes whenever any of them
+// does.
+func RevisionID(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) string {
+	id := strconv.FormatInt(pdst.GetGeneration(), 10)
+	for _, base := range pdst.Status.Bases {
+		id += "." + strconv.FormatInt(base.Generation, 10)
+	}
+	return id
+}
+
+// RevisionName returns the name of the revision with the given ID taken from
+// the template with the given name
+func RevisionName(templateName string, id string) string {
+	return fmt.Sprintf("%s-%s", templateName, id)
+}
+
+// Get fetches the template the given stream refers to. A
+// ClusterProjectDevelopmentStreamTemplate is returned converted into a
+// ProjectDevelopmentStreamTemplate with no namespace, so that it can be used
+// with MkResources. If the stream is pinned to a template revision, the
+// revision is returned converted into a template with the name and generation
+// of the template it was taken from, and the extended templates it was taken
+// with in its status. Revisions are taken with the extended templates already
+// merged into them. Otherwise, the templates the template extends are merged
+// into it with Resolve.
+func Get(
+	ctx context.Context,
+	c client.Reader,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+	ref := pds.Spec.Template
+	if IsPinned(ref) {
+		return getRevision(ctx, c, pds)
+	}
+	pdst, err := getCurrent(ctx, c, pds)
+	if err != nil {
+		return nil, err
+	}
+	return Resolve(ctx, c, pdst)
+}
+
+// Fetch the current spec of the template the given stream refers to, without
+// merging the templates it extends into it
+func getCurrent(
+	ctx context.Context,
+	c client.Reader,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+	ref := pds.Spec.Template
+	if RefKind(ref) == projctlv1beta1.TemplateKindCluster {
+		var cpdst projctlv1beta1.ClusterProjectDevelopmentStreamTemplate
+		if err := c.Get(ctx, client.ObjectKey{Name: ref.Name}, &cpdst); err != nil {
+			return nil, err
+		}
+		return &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			TypeMeta:   cpdst.TypeMeta,
+			ObjectMeta: cpdst.ObjectMeta,
+			Spec:       cpdst.Spec,
+			Status:     cpdst.Status,
+		}, nil
+	}
+	var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+	if err := c.Get(ctx, client.ObjectKey{Namespace: pds.GetNamespace(), Name: ref.Name}, &pdst); err != nil {
+		return nil, err
+	}
+	return &pdst, nil
+}
+
+// GetProject fetches the Project the given stream belongs to, so it can be
+// passed to MkResources. Returns nil without an error if the stream does not
+// name a project or the project does not exist.
+func GetProject(
+	ctx context.Context,
+	c client.Reader,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+) (*projctlv1beta1.Project, error) {
+	if pds.Spec.Project == "" {
+		return nil, nil
+	}
+	var project projctlv1beta1.Project
+	if err := c.Get(ctx, client.ObjectKey{Namespace: pds.GetNamespace(), Name: pds.Spec.Project}, &project); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return &project, nil
+}
+
+// Fetch the template revision the given stream is pinned to
+func getRevision(
+	ctx context.Context,
+	c client.Reader,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplate, error) {
+	ref := pds.Spec.Template
+	key := client.ObjectKey{Name: RevisionName(ref.Name, ref.Revision)}
+	if RefKind(ref) == projctlv1beta1.TemplateKindCluster {
+		var rev projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision
+		if err := c.Get(ctx, key, &rev); err != nil {
+			return nil, err
+		}
+		return &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Generation: rev.Revision},
+			Spec:       rev.Spec,
+			Status:     projctlv1beta1.ProjectDevelopmentStreamTemplateStatus{Bases: rev.Bases},
+		}, nil
+	}
+	key.Namespace = pds.GetNamespace()
+	var rev projctlv1beta1.ProjectDevelopmentStreamTemplateRevision
+	if err := c.Get(ctx, key, &rev); err != nil {
+		return nil, err
+	}
+	return &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: rev.Namespace, Name: ref.Name, Generation: rev.Revision},
+		Spec:       rev.Spec,
+		Status:     projctlv1beta1.ProjectDevelopmentStreamTemplateStatus{Bases: rev.Bases},
+	}, nil
+}
@@ -0,0 +1,84 @@
+package template
+
+import (
+	"fmt"
+	"maps"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// Names under which the current item of a for-each resource, and its position
+// in the list, are available to the resource templates. They take precedence
+// over template variables with the same names.
+const (
+	itemKey      = "item"
+	itemIndexKey = "itemIndex"
+)
+
+// Returns the variable with the given name that the for-each annotation of the
+// given template resource refers to, checking that it is a variable whose
+// values are lists
+func forEachVar(
+	resource *unstructured.Unstructured,
+	vars []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable,
+	varName string,
+) (*projctlv1beta1.ProjectDevelopmentStreamTemplateVariable, error) {
+	var variable *projctlv1beta1.ProjectDevelopmentStreamTemplateVariable
+	// The last definition of duplicate variables is the one that takes effect
+	for i := range vars {
+		if vars[i].Name == varName {
+			variable = &vars[i]
+		}
+	}
+	if variable == nil {
+		return nil, fmt.Errorf(
+			"%s '%s' is repeated for each item of undefined template variable '%s'",
+			resource.GetKind(), resource.GetName(), varName,
+		)
+	}
+	if variable.Type != projctlv1beta1.VariableTypeList && variable.Type != projctlv1beta1.VariableTypeYAML {
+		return nil, fmt.Errorf(
+			"%s '%s' is repeated for each item of template variable '%s', which is not of the list or yaml type",
+			resource.GetKind(), resource.GetName(), varName,
+		)
+	}
+	return variable, nil
+}
+
+// Returns the data to process the given template resource with for each copy
+// of the resource to generate, and removes the for-each annotation from the
+// resource. Resources without the annotation are generated once with the given
+// data.
+func forEachData(
+	resource *unstructured.Unstructured,
+	vars []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable,
+	data map[string]any,
+) ([]map[string]any, error) {
+	varName, ok := popAnnotation(resource, projctlv1beta1.ForEachAnnotation)
+	if !ok {
+		return []map[string]any{data}, nil
+	}
+	if _, err := forEachVar(resource, vars, varName); err != nil {
+		return nil, err
+	}
+	// The values of both list and yaml variables are passed to templates as
+	// []any, see templateValue
+	var items []any
+	if value := data[varName]; value != nil {
+		if items, ok = value.([]any); !ok {
+			return nil, fmt.Errorf(
+				"%s '%s' is repeated for each item of template variable '%s', whose value is not a list",
+				resource.GetKind(), resource.GetName(), varName,
+			)
+		}
+	}
+	itemsData := make([]map[string]any, len(items))
+	for i, item := range items {
+		itemsData[i] = maps.Clone(data)
+		itemsData[i][itemKey] = item
+		itemsData[i][itemIndexKey] = i
+	}
+	return itemsData, nil
+}
@@ -0,0 +1,81 @@
+package template
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("forEachData", func() {
+	vars := []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+		{Name: "version"},
+		{Name: "archs", Type: projctlv1beta1.VariableTypeList},
+		{Name: "components", Type: projctlv1beta1.VariableTypeYAML},
+	}
+
+	mkResource := func(forEach string) *unstructured.Unstructured {
+		resource := &unstructured.Unstructured{}
+		resource.SetKind("Component")
+		resource.SetName("comp-{{.item}}")
+		resource.SetAnnotations(map[string]string{projctlv1beta1.ForEachAnnotation: forEach})
+		return resource
+	}
+
+	It("returns the given data for resources that are not repeated", func() {
+		resource := &unstructured.Unstructured{}
+		data := map[string]any{"version": "1.0.0"}
+		Expect(forEachData(resource, vars, data)).To(Equal([]map[string]any{data}))
+	})
+
+	It("repeats resources for each item of list variables", func() {
+		resource := mkResource("archs")
+		archs := []any{"amd64", "arm64"}
+		Expect(forEachData(resource, vars, map[string]any{"archs": archs})).To(Equal([]map[string]any{
+			{"archs": archs, "item": "amd64", "itemIndex": 0},
+			{"archs": archs, "item": "arm64", "itemIndex": 1},
+		}))
+		Expect(resource.GetAnnotations()).To(BeEmpty())
+	})
+
+	It("repeats resources for each item of yaml variables", func() {
+		components := []any{map[string]any{"name": "comp1"}}
+		Expect(forEachData(mkResource("components"), vars, map[string]any{"components": components})).To(Equal(
+			[]map[string]any{{"components": components, "item": components[0], "itemIndex": 0}},
+		))
+	})
+
+	It("generates nothing for empty lists", func() {
+		Expect(forEachData(mkResource("archs"), vars, map[string]any{"archs": []any{}})).To(BeEmpty())
+		Expect(forEachData(mkResource("components"), vars, map[string]any{"components": nil})).To(BeEmpty())
+	})
+
+	DescribeTable(
+		"it reports bad for-each annotations",
+		func(forEach string, data map[string]any, expected string) {
+			_, err := forEachData(mkResource(forEach), vars, data)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry(
+			"undefined variables",
+			"nosuchvar",
+			map[string]any{},
+			"Component 'comp-{{.item}}' is repeated for each item of undefined template variable 'nosuchvar'",
+		),
+		Entry(
+			"variables that are not lists",
+			"version",
+			map[string]any{"version": "1.0.0"},
+			"Component 'comp-{{.item}}' is repeated for each item of template variable 'version', "+
+				"which is not of the list or yaml type",
+		),
+		Entry(
+			"yaml values that are not lists",
+			"components",
+			map[string]any{"components": map[string]any{"name": "comp1"}},
+			"Component 'comp-{{.item}}' is repeated for each item of template variable 'components', "+
+				"whose value is not a list",
+		),
+	)
+})
@@ -0,0 +1,113 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// Fields identifying a resource or its owner, which patches cannot change.
+// The field holding the owner name, if the resource type has one, cannot be
+// changed either.
+var protectedFields = [][]string{
+	{"apiVersion"}, {"kind"}, {"metadata", "name"}, {"metadata", "namespace"}, {"metadata", "ownerReferences"},
+}
+
+// Apply the given patches, in order, to the matching resources. Untouchable
+// fields set by the patches are removed again. Patches that do not match any
+// of the resources are reported, unless they match one of the given resources
+// that were excluded by their inclusion conditions. All the issues found are
+// returned, joined into a single error.
+func applyPatches(
+	patches []projctlv1beta1.ProjectDevelopmentStreamPatch,
+	resources []*unstructured.Unstructured,
+	excluded []projctlv1beta1.ProjectDevelopmentStreamExcludedResource,
+) error {
+	var errs []error
+	for i, patch := range patches {
+		target := patch.Target
+		matched := false
+		for _, resource := range resources {
+			if resource.GetKind() != target.Kind || resource.GetName() != target.Name {
+				continue
+			}
+			matched = true
+			if err := applyPatch(resource, patch); err != nil {
+				errs = append(errs, fmt.Errorf("patch #%d (%s '%s'): %w", i, target.Kind, target.Name, err))
+			}
+		}
+		if !matched && !slices.ContainsFunc(excluded, func(res projctlv1beta1.ProjectDevelopmentStreamExcludedResource) bool {
+			return res.Kind == target.Kind && res.Name == target.Name
+		}) {
+			errs = append(errs, fmt.Errorf(
+				"patch #%d targets %s '%s', which is not generated from the template", i, target.Kind, target.Name,
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply the given patch to the given resource. Name fields are validated
+// again after patching.
+func applyPatch(resource *unstructured.Unstructured, patch projctlv1beta1.ProjectDevelopmentStreamPatch) error {
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return fmt.Errorf("failed to parse patch: %w", err)
+	}
+	original, err := json.Marshal(resource.Object)
+	if err != nil {
+		return err
+	}
+	var patchedJSON []byte
+	switch patch.Type {
+	case projctlv1beta1.PatchTypeJSON:
+		ops, err := jsonpatch.DecodePatch(patchJSON)
+		if err != nil {
+			return fmt.Errorf("failed to parse patch: %w", err)
+		}
+		if patchedJSON, err = ops.Apply(original); err != nil {
+			return fmt.Errorf("failed to apply patch: %w", err)
+		}
+	default:
+		var mergePatch map[string]any
+		if err := utiljson.Unmarshal(patchJSON, &mergePatch); err != nil {
+			return fmt.Errorf("failed to parse patch, expected a partial resource: %w", err)
+		}
+		if patchedJSON, err = jsonpatch.MergePatch(original, patchJSON); err != nil {
+			return fmt.Errorf("failed to apply patch: %w", err)
+		}
+	}
+	var patched map[string]any
+	if err := utiljson.Unmarshal(patchedJSON, &patched); err != nil {
+		return err
+	}
+
+	srt := findResourceType(resource.GroupVersionKind())
+	protected := protectedFields
+	if srt != nil && len(srt.ownerNameField) > 0 {
+		protected = append(slices.Clone(protected), srt.ownerNameField)
+	}
+	for _, path := range protected {
+		before, _, _ := unstructured.NestedFieldNoCopy(resource.Object, path...)
+		after, _, _ := unstructured.NestedFieldNoCopy(patched, path...)
+		if !reflect.DeepEqual(before, after) {
+			return fmt.Errorf("patches cannot change the %s field", strings.Join(path, "."))
+		}
+	}
+	resource.Object = patched
+	if srt == nil {
+		return nil
+	}
+	removeUntouchableFields(resource, srt.untouchableFields)
+	return validateResourceNameFields(resource, srt.templateAbleNameFields)
+}
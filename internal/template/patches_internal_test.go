@@ -0,0 +1,176 @@
+package template
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("applyPatches", func() {
+	var resources []*unstructured.Unstructured
+
+	BeforeEach(func() {
+		resources = []*unstructured.Unstructured{
+			{Object: map[string]any{
+				"apiVersion": "appstudio.redhat.com/v1alpha1",
+				"kind":       "Component",
+				"metadata":   map[string]any{"name": "comp", "namespace": "my-ns"},
+				"spec": map[string]any{
+					"application":   "app",
+					"componentName": "comp",
+					"source":        map[string]any{"git": map[string]any{"revision": "main", "context": "./"}},
+				},
+			}},
+			{Object: map[string]any{
+				"apiVersion": "appstudio.redhat.com/v1beta2",
+				"kind":       "IntegrationTestScenario",
+				"metadata":   map[string]any{"name": "its", "namespace": "my-ns"},
+				"spec": map[string]any{
+					"params": []any{
+						map[string]any{"name": "a", "value": "1"},
+						map[string]any{"name": "b", "value": "2"},
+					},
+					"contexts": []any{map[string]any{"name": "push"}},
+				},
+			}},
+		}
+	})
+
+	patch := func(kind, name string, patchType projctlv1beta1.PatchType, patch string) projctlv1beta1.ProjectDevelopmentStreamPatch {
+		return projctlv1beta1.ProjectDevelopmentStreamPatch{
+			Target: projctlv1beta1.ProjectDevelopmentStreamPatchTarget{Kind: kind, Name: name},
+			Type:   patchType,
+			Patch:  patch,
+		}
+	}
+
+	It("merges partial resources into the matching resource", func() {
+		Expect(applyPatches([]projctlv1beta1.ProjectDevelopmentStreamPatch{
+			patch("Component", "comp", "", "spec: {source: {git: {revision: v2, context: null}}, containerImage: img}"),
+		}, resources, nil)).To(Succeed())
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", map[string]any{
+			"application":    "app",
+			"componentName":  "comp",
+			"containerImage": "img",
+			"source":         map[string]any{"git": map[string]any{"revision": "v2"}},
+		}))
+		Expect(resources[1].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("params", HaveLen(2))))
+	})
+
+	It("replaces lists as a whole, as RFC 7386 merge patches do", func() {
+		resources[0].Object["spec"].(map[string]any)["build-nudges-ref"] = []any{"x", "y"}
+		Expect(applyPatches([]projctlv1beta1.ProjectDevelopmentStreamPatch{
+			patch("IntegrationTestScenario", "its", projctlv1beta1.PatchTypeMerge, `
+spec:
+  params:
+  - name: c
+    value: "3"
+`),
+			patch("Component", "comp", "", `{"spec": {"build-nudges-ref": ["z"]}}`),
+		}, resources, nil)).To(Succeed())
+		Expect(resources[1].Object).To(HaveKeyWithValue("spec", map[string]any{
+			"params":   []any{map[string]any{"name": "c", "value": "3"}},
+			"contexts": []any{map[string]any{"name": "push"}},
+		}))
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("build-nudges-ref", []any{"z"})))
+	})
+
+	It("applies JSON patches", func() {
+		Expect(applyPatches([]projctlv1beta1.ProjectDevelopmentStreamPatch{
+			patch("IntegrationTestScenario", "its", projctlv1beta1.PatchTypeJSON, `
+- op: add
+  path: /spec/params/-
+  value: {name: c, value: "3"}
+- op: replace
+  path: /spec/params/0/value
+  value: "0"
+`),
+		}, resources, nil)).To(Succeed())
+		Expect(resources[1].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("params", []any{
+			map[string]any{"name": "a", "value": "0"},
+			map[string]any{"name": "b", "value": "2"},
+			map[string]any{"name": "c", "value": "3"},
+		})))
+	})
+
+	It("applies patches in order", func() {
+		Expect(applyPatches([]projctlv1beta1.ProjectDevelopmentStreamPatch{
+			patch("Component", "comp", "", "spec: {containerImage: first}"),
+			patch("Component", "comp", projctlv1beta1.PatchTypeJSON, `[{"op": "test", "path": "/spec/containerImage", "value": "first"}]`),
+			patch("Component", "comp", "", "spec: {containerImage: second}"),
+		}, resources, nil)).To(Succeed())
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("containerImage", "second")))
+	})
+
+	It("does not let patches set untouchable fields", func() {
+		Expect(applyPatches([]projctlv1beta1.ProjectDevelopmentStreamPatch{
+			patch("Component", "comp", "", `
+metadata:
+  annotations:
+    appstudio.openshift.io/request: configure-pac
+    other: value
+`),
+		}, resources, nil)).To(Succeed())
+		Expect(resources[0].GetAnnotations()).To(Equal(map[string]string{"other": "value"}))
+	})
+
+	DescribeTable(
+		"reports invalid patches",
+		func(p projctlv1beta1.ProjectDevelopmentStreamPatch, expected string) {
+			Expect(applyPatches([]projctlv1beta1.ProjectDevelopmentStreamPatch{p}, resources, nil)).To(
+				MatchError(ContainSubstring(expected)),
+			)
+		},
+		Entry(
+			"no matching resource",
+			patch("Component", "other", "", "spec: {}"),
+			"patch #0 targets Component 'other', which is not generated from the template",
+		),
+		Entry(
+			"changing the name",
+			patch("Component", "comp", "", "metadata: {name: other}"),
+			"patch #0 (Component 'comp'): patches cannot change the metadata.name field",
+		),
+		Entry(
+			"changing the owner",
+			patch("Component", "comp", "", "spec: {application: other}"),
+			"patches cannot change the spec.application field",
+		),
+		Entry(
+			"changing the owner references",
+			patch("Component", "comp", projctlv1beta1.PatchTypeJSON,
+				`[{"op": "add", "path": "/metadata/ownerReferences", "value": []}]`),
+			"patches cannot change the metadata.ownerReferences field",
+		),
+		Entry(
+			"setting invalid names",
+			patch("Component", "comp", "", "spec: {componentName: Bad Name}"),
+			"invalid resource name value 'Bad Name' for resource field 'spec.componentName'",
+		),
+		Entry(
+			"changing the kind",
+			patch("Component", "comp", projctlv1beta1.PatchTypeJSON, `[{"op": "replace", "path": "/kind", "value": "Application"}]`),
+			"patches cannot change the kind field",
+		),
+		Entry(
+			"a merge patch that is not a map",
+			patch("Component", "comp", "", "- a"),
+			"expected a partial resource",
+		),
+		Entry(
+			"a JSON patch that fails to apply",
+			patch("Component", "comp", projctlv1beta1.PatchTypeJSON, `[{"op": "remove", "path": "/spec/nosuchfield"}]`),
+			"failed to apply patch",
+		),
+	)
+
+	It("ignores patches of resources excluded by their inclusion conditions", func() {
+		Expect(applyPatches(
+			[]projctlv1beta1.ProjectDevelopmentStreamPatch{patch("Component", "other", "", "spec: {}")},
+			resources,
+			[]projctlv1beta1.ProjectDevelopmentStreamExcludedResource{{Kind: "Component", Name: "other"}},
+		)).To(Succeed())
+	})
+})
@@ -0,0 +1,170 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+
+	apischema "k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// The resource type policies currently in effect and the resource types
+// resulting from merging them with supportedResourceTypes
+var registry = struct {
+	sync.RWMutex
+	policies      []projctlv1beta1.ResourceTypePolicySpec
+	resourceTypes []resourceType
+}{
+	resourceTypes: supportedResourceTypes,
+}
+
+// Returns the resource types currently supported by templates
+func resourceTypes() []resourceType {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.resourceTypes
+}
+
+// SetResourceTypePolicies replaces the resource type policies that are merged
+// with the built-in resource types to determine which resource types are
+// supported by templates and how they are handled. The policies are expected
+// to have been checked with CheckResourceTypePolicy. Returns true if the
+// policies differ from the ones previously set.
+func SetResourceTypePolicies(policies []projctlv1beta1.ResourceTypePolicySpec) bool {
+	registry.Lock()
+	defer registry.Unlock()
+	if reflect.DeepEqual(policies, registry.policies) {
+		return false
+	}
+	registry.policies = policies
+	registry.resourceTypes = mergeResourceTypePolicies(supportedResourceTypes, policies)
+	return true
+}
+
+// Returns the given resource types with the given policies merged into them.
+// A policy that applies to a GVK of one of the given types replaces that type
+// in place, other policies are added at the end of the list in the order
+// they are given.
+func mergeResourceTypePolicies(
+	resourceTypes []resourceType,
+	policies []projctlv1beta1.ResourceTypePolicySpec,
+) []resourceType {
+	merged := slices.Clone(resourceTypes)
+	for _, policy := range policies {
+		rt := resourceTypeFromPolicy(policy)
+		i := slices.IndexFunc(merged, func(existing resourceType) bool {
+			return slices.ContainsFunc(rt.supportedAPIs, func(gvk apischema.GroupVersionKind) bool {
+				return findGVK(existing.supportedAPIs, gvk)
+			})
+		})
+		if i >= 0 {
+			merged[i] = rt
+		} else {
+			merged = append(merged, rt)
+		}
+	}
+	return merged
+}
+
+// CheckResourceTypePolicy checks that the given policy is valid and can be
+// merged with the given previously accepted policies. A policy may not apply
+// to GVKs that other accepted policies apply to, and it may only replace a
+// single built-in resource type.
+func CheckResourceTypePolicy(
+	accepted []projctlv1beta1.ResourceTypePolicySpec,
+	policy projctlv1beta1.ResourceTypePolicySpec,
+) error {
+	if len(policy.APIs) == 0 {
+		return errors.New("resource type policy does not list any APIs")
+	}
+	rt := resourceTypeFromPolicy(policy)
+	for _, gvk := range rt.supportedAPIs {
+		if gvk.Version == "" || gvk.Kind == "" {
+			return fmt.Errorf("API version and kind must be set for resource type: %s", gvk)
+		}
+		for _, other := range accepted {
+			if findGVK(resourceTypeFromPolicy(other).supportedAPIs, gvk) {
+				return fmt.Errorf("resource type already defined by another policy: %s", gvk)
+			}
+		}
+	}
+	var replaced int
+	for _, srt := range supportedResourceTypes {
+		if slices.ContainsFunc(rt.supportedAPIs, func(gvk apischema.GroupVersionKind) bool {
+			return findGVK(srt.supportedAPIs, gvk)
+		}) {
+			replaced++
+		}
+	}
+	if replaced > 1 {
+		return errors.New("resource type policy applies to more than one built-in resource type")
+	}
+	if policy.Owner != nil && policy.Owner.API.Kind == "" {
+		return errors.New("owner kind must be set in resource type policy")
+	}
+	return nil
+}
+
+// LoadResourceTypeConfig reads resource type policies from the given
+// configuration file. The file is expected to hold a YAML document with a
+// 'resourceTypes' list where each item has the same structure as the spec of
+// a ResourceTypePolicy.
+func LoadResourceTypeConfig(path string) ([]projctlv1beta1.ResourceTypePolicySpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		ResourceTypes []projctlv1beta1.ResourceTypePolicySpec `json:"resourceTypes"`
+	}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse resource type configuration '%s': %w", path, err)
+	}
+	for i, policy := range config.ResourceTypes {
+		if err := CheckResourceTypePolicy(config.ResourceTypes[:i], policy); err != nil {
+			return nil, fmt.Errorf("invalid resource type #%d in '%s': %w", i, path, err)
+		}
+	}
+	return config.ResourceTypes, nil
+}
+
+// Convert a resource type policy into the internal resource type details
+func resourceTypeFromPolicy(policy projctlv1beta1.ResourceTypePolicySpec) resourceType {
+	rt := resourceType{
+		templateAbleFields:         fieldPaths(policy.TemplatableFields),
+		templateAbleNameFields:     fieldPaths(policy.TemplatableNameFields),
+		untouchableFields:          fieldPaths(policy.UntouchableFields),
+		createOnlyFields:           fieldPaths(policy.CreateOnlyFields),
+		liveStateConditionalFields: fieldPaths(policy.LiveStateConditionalFields),
+	}
+	for _, api := range policy.APIs {
+		rt.supportedAPIs = append(rt.supportedAPIs, apischema.GroupVersionKind{
+			Group: api.Group, Version: api.Version, Kind: api.Kind,
+		})
+	}
+	if policy.Owner != nil {
+		rt.ownerNameField = policy.Owner.NameField
+		rt.ownerAPI = apischema.GroupVersionKind{
+			Group:   policy.Owner.API.Group,
+			Version: policy.Owner.API.Version,
+			Kind:    policy.Owner.API.Kind,
+		}
+		rt.ownerIsController = policy.Owner.Controller
+		rt.ownerDeletionBlocked = policy.Owner.BlockOwnerDeletion
+	}
+	return rt
+}
+
+func fieldPaths(paths []projctlv1beta1.FieldPath) [][]string {
+	var ret [][]string
+	for _, path := range paths {
+		ret = append(ret, path)
+	}
+	return ret
+}
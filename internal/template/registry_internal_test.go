@@ -0,0 +1,190 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apischema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var rpaPolicy = projctlv1beta1.ResourceTypePolicySpec{
+	APIs: []projctlv1beta1.ResourceTypeAPI{
+		{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "ReleasePlanAdmission"},
+	},
+	TemplatableNameFields: []projctlv1beta1.FieldPath{{"metadata", "name"}},
+	TemplatableFields:     []projctlv1beta1.FieldPath{{"spec", "origin"}},
+	Owner: &projctlv1beta1.ResourceTypeOwner{
+		NameField: projctlv1beta1.FieldPath{"spec", "application"},
+		API:       projctlv1beta1.ResourceTypeAPI{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Application"},
+	},
+}
+
+var applicationPolicy = projctlv1beta1.ResourceTypePolicySpec{
+	APIs: []projctlv1beta1.ResourceTypeAPI{
+		{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Application"},
+	},
+	TemplatableNameFields: []projctlv1beta1.FieldPath{{"metadata", "name"}},
+}
+
+var _ = Describe("mergeResourceTypePolicies", func() {
+	It("adds new resource types at the end", func() {
+		merged := mergeResourceTypePolicies(supportedResourceTypes, []projctlv1beta1.ResourceTypePolicySpec{rpaPolicy})
+		Expect(merged).To(HaveLen(len(supportedResourceTypes) + 1))
+		Expect(merged[:len(supportedResourceTypes)]).To(Equal(supportedResourceTypes))
+		Expect(merged[len(supportedResourceTypes)]).To(Equal(resourceType{
+			supportedAPIs: []apischema.GroupVersionKind{
+				{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "ReleasePlanAdmission"},
+			},
+			templateAbleNameFields: [][]string{{"metadata", "name"}},
+			templateAbleFields:     [][]string{{"spec", "origin"}},
+			ownerNameField:         []string{"spec", "application"},
+			ownerAPI:               apischema.GroupVersionKind{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Application"},
+		}))
+	})
+
+	It("replaces built-in resource types in place", func() {
+		merged := mergeResourceTypePolicies(supportedResourceTypes, []projctlv1beta1.ResourceTypePolicySpec{applicationPolicy})
+		Expect(merged).To(HaveLen(len(supportedResourceTypes)))
+		Expect(merged[0].templateAbleFields).To(BeEmpty())
+		Expect(supportedResourceTypes[0].templateAbleFields).NotTo(BeEmpty())
+	})
+})
+
+var _ = Describe("CheckResourceTypePolicy", func() {
+	It("accepts valid policies", func() {
+		Expect(CheckResourceTypePolicy(nil, rpaPolicy)).To(Succeed())
+		Expect(CheckResourceTypePolicy([]projctlv1beta1.ResourceTypePolicySpec{rpaPolicy}, applicationPolicy)).To(Succeed())
+	})
+
+	DescribeTable(
+		"rejects invalid policies",
+		func(accepted []projctlv1beta1.ResourceTypePolicySpec, modify func(*projctlv1beta1.ResourceTypePolicySpec), expected string) {
+			policy := *rpaPolicy.DeepCopy()
+			modify(&policy)
+			Expect(CheckResourceTypePolicy(accepted, policy)).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry(
+			"without APIs",
+			nil,
+			func(p *projctlv1beta1.ResourceTypePolicySpec) { p.APIs = nil },
+			"does not list any APIs",
+		),
+		Entry(
+			"without a kind",
+			nil,
+			func(p *projctlv1beta1.ResourceTypePolicySpec) { p.APIs[0].Kind = "" },
+			"API version and kind must be set",
+		),
+		Entry(
+			"conflicting with other policies",
+			[]projctlv1beta1.ResourceTypePolicySpec{rpaPolicy},
+			func(p *projctlv1beta1.ResourceTypePolicySpec) {},
+			"resource type already defined by another policy: appstudio.redhat.com/v1alpha1, Kind=ReleasePlanAdmission",
+		),
+		Entry(
+			"replacing multiple built-in types",
+			nil,
+			func(p *projctlv1beta1.ResourceTypePolicySpec) {
+				p.APIs = append(applicationPolicy.APIs, projctlv1beta1.ResourceTypeAPI{
+					Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Component",
+				})
+			},
+			"applies to more than one built-in resource type",
+		),
+		Entry(
+			"without an owner kind",
+			nil,
+			func(p *projctlv1beta1.ResourceTypePolicySpec) { p.Owner.API.Kind = "" },
+			"owner kind must be set",
+		),
+	)
+})
+
+var _ = Describe("LoadResourceTypeConfig", func() {
+	writeConfig := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "resource-types.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+
+	It("loads resource types from the file", func() {
+		policies, err := LoadResourceTypeConfig(writeConfig(`
+resourceTypes:
+- apis:
+  - {group: appstudio.redhat.com, version: v1alpha1, kind: ReleasePlanAdmission}
+  templatableNameFields:
+  - [metadata, name]
+  templatableFields:
+  - [spec, origin]
+  owner:
+    nameField: [spec, application]
+    api: {group: appstudio.redhat.com, version: v1alpha1, kind: Application}
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(Equal([]projctlv1beta1.ResourceTypePolicySpec{rpaPolicy}))
+	})
+
+	It("rejects unknown fields", func() {
+		_, err := LoadResourceTypeConfig(writeConfig("resourceTypes:\n- apis: []\n  nosuchfield: foo\n"))
+		Expect(err).To(MatchError(ContainSubstring("failed to parse resource type configuration")))
+	})
+
+	It("rejects invalid resource types", func() {
+		_, err := LoadResourceTypeConfig(writeConfig("resourceTypes:\n- apis: []\n"))
+		Expect(err).To(MatchError(ContainSubstring("invalid resource type #0")))
+	})
+})
+
+var _ = Describe("SetResourceTypePolicies", func() {
+	AfterEach(func() {
+		SetResourceTypePolicies(nil)
+	})
+
+	It("makes policy resource types supported in templates", func() {
+		pds := projctlv1beta1.ProjectDevelopmentStream{
+			Spec: projctlv1beta1.ProjectDevelopmentStreamSpec{
+				Template: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{},
+			},
+		}
+		pdst := projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+				Variables: []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+					{Name: "version", DefaultValue: new("1-0")},
+				},
+				Resources: []projctlv1beta1.UnstructuredObj{
+					{Unstructured: unstructured.Unstructured{Object: map[string]any{
+						"apiVersion": "appstudio.redhat.com/v1alpha1",
+						"kind":       "ReleasePlanAdmission",
+						"metadata":   map[string]any{"name": "rpa-{{.version}}"},
+						"spec": map[string]any{
+							"application": "my-app",
+							"origin":      "origin-{{.version}}",
+						},
+					}}},
+				},
+			},
+		}
+
+		_, _, err := MkResources(pds, pdst, nil)
+		Expect(err).To(MatchError(ContainSubstring("unsupported resource type in template")))
+
+		Expect(SetResourceTypePolicies([]projctlv1beta1.ResourceTypePolicySpec{rpaPolicy})).To(BeTrue())
+		Expect(SetResourceTypePolicies([]projctlv1beta1.ResourceTypePolicySpec{rpaPolicy})).To(BeFalse())
+		Expect(ResourceAPIs()).To(ContainElement(apischema.GroupVersionKind{
+			Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "ReleasePlanAdmission",
+		}))
+		Expect(Validate(pdst)).To(Succeed())
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(1))
+		Expect(resources[0].GetName()).To(Equal("rpa-1-0"))
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("origin", "origin-1-0")))
+		Expect(resources[0].GetOwnerReferences()).To(HaveLen(1))
+		Expect(resources[0].GetOwnerReferences()[0].Name).To(Equal("my-app"))
+	})
+})
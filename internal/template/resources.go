@@ -1,8 +1,10 @@
 package template
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
@@ -18,11 +20,8 @@ import (
 // +kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationtestscenarios,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=appstudio.redhat.com,resources=releaseplans,verbs=get;list;watch;create;update;patch;delete
 
-// List of resource types supported by templates and various details about how
-// to instantiate resources of those types. The list order determines the order
-// in which resources are created, which can be significant for e.g. creating
-// ownership relationships
-var supportedResourceTypes = []struct {
+// Details about how to instantiate resources of a type supported by templates
+type resourceType struct {
 	// The supported API group/version/kind values for this resource.
 	supportedAPIs []apischema.GroupVersionKind
 	// The list of template-able fields for the resource. Each member is a list
@@ -51,7 +50,14 @@ var supportedResourceTypes = []struct {
 	ownerIsController bool
 	// The owner object deletion should be blocked
 	ownerDeletionBlocked bool
-}{
+}
+
+// List of resource types supported by templates out of the box and various
+// details about how to instantiate resources of those types. The list order
+// determines the order in which resources are created, which can be
+// significant for e.g. creating ownership relationships. Resource type
+// policies may replace or add to these types (see registry.go)
+var supportedResourceTypes = []resourceType{
 	{
 		supportedAPIs: []apischema.GroupVersionKind{
 			{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Application"},
@@ -122,9 +128,6 @@ var supportedResourceTypes = []struct {
 		templateAbleNameFields: [][]string{
 			{"metadata", "name"},
 			{"spec", "application"},
-			// TODO: allow templating of spec.params
-			// which is an array of name/value pairs. This would require changes to
-			// applyResourceTemplate and possibly validateResourceNameFields
 		},
 		templateAbleFields: [][]string{
 			{"spec", "params", "[]", "value"},
@@ -164,11 +167,16 @@ var supportedResourceTypes = []struct {
 }
 
 // Make the resources to be owned by the given ProjectDevelopmentStream as
-// defined by the given  ProjectDevelopmentStreamTemplate
+// defined by the given  ProjectDevelopmentStreamTemplate. The given Project,
+// which may be nil if it does not exist, is made available to the templates
+// along with the stream. The patches of the stream are applied to the
+// generated resources. The resources of the template left out for the stream
+// by their inclusion conditions are returned as well.
 func MkResources(
 	pds projctlv1beta1.ProjectDevelopmentStream,
 	pdst projctlv1beta1.ProjectDevelopmentStreamTemplate,
-) ([]*unstructured.Unstructured, error) {
+	project *projctlv1beta1.Project,
+) ([]*unstructured.Unstructured, []projctlv1beta1.ProjectDevelopmentStreamExcludedResource, error) {
 	resources := make([]*unstructured.Unstructured, 0, len(pdst.Spec.Resources))
 	// unhandledTemplates is used to detect unsupported resource types that may
 	// have been included in the template
@@ -176,57 +184,195 @@ func MkResources(
 	for i := range pdst.Spec.Resources {
 		unhandledTemplates[i] = true
 	}
-	templateVarValues, err := getVarValues(pdst.Spec.Variables, pds.Spec.Template.Values)
+	var excluded []projctlv1beta1.ProjectDevelopmentStreamExcludedResource
+	builtins := builtinContext(&pds, project)
+	varValues, err := getVarValues(pdst.Spec.Variables, pds.Spec.Template.Values, builtins)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	for _, srt := range supportedResourceTypes {
+	templateVarValues := templateData(builtins, varValues)
+	for _, srt := range resourceTypes() {
 		for i, unstructuredObj := range pdst.Spec.Resources {
 			if !findGVK(srt.supportedAPIs, unstructuredObj.GroupVersionKind()) {
 				continue
 			}
 			unhandledTemplates[i] = false
-			resource := unstructuredObj.Unstructured.DeepCopy()
-			resource.SetNamespace(pds.GetNamespace())
+			resourceTemplate := unstructuredObj.Unstructured.DeepCopy()
+			resourceTemplate.SetNamespace(pds.GetNamespace())
 
-			// Remove untouchable fields from the template before processing
-			removeUntouchableFields(resource, srt.untouchableFields)
-
-			if err := applyResourceTemplate(resource, srt.templateAbleNameFields, templateVarValues); err != nil {
-				return nil, err
-			}
-			if err := validateResourceNameFields(resource, srt.templateAbleNameFields); err != nil {
-				return nil, err
-			}
-			if err := applyResourceTemplate(resource, srt.templateAbleFields, templateVarValues); err != nil {
-				return nil, err
+			itemsData, err := forEachData(resourceTemplate, pdst.Spec.Variables, templateVarValues)
+			if err != nil {
+				return nil, nil, err
 			}
-			if srt.ownerNameField != nil {
-				ownerName, ok, err := unstructured.NestedString(resource.Object, srt.ownerNameField...)
-				if ok && err == nil {
-					// If we can't find the owner name field, we just skip
-					// setting an owner
-					ownership.SetWithoutUid(
-						resource,
-						srt.ownerAPI,
-						ownerName,
-						srt.ownerIsController,
-						srt.ownerDeletionBlocked,
+			generatedNames := make(map[string]bool, len(itemsData))
+			for _, data := range itemsData {
+				resource := resourceTemplate.DeepCopy()
+				include, condition, err := evalIncludeIf(resource, data)
+				if err != nil {
+					return nil, nil, err
+				}
+				if !include {
+					excluded = append(excluded, excludedResource(resource, condition, data))
+					continue
+				}
+				if err := renderResource(srt, resource, pdst.Spec, data); err != nil {
+					return nil, nil, err
+				}
+				if generatedNames[resource.GetName()] {
+					return nil, nil, fmt.Errorf(
+						"%s '%s' is generated more than once by the %s annotation, "+
+							"consider using .item in its name",
+						resource.GetKind(), resource.GetName(), projctlv1beta1.ForEachAnnotation,
 					)
 				}
+				generatedNames[resource.GetName()] = true
+				resources = append(resources, resource)
 			}
-			resources = append(resources, resource)
 		}
 	}
 	for i, unstructuredObj := range pdst.Spec.Resources {
 		if unhandledTemplates[i] {
-			return nil, fmt.Errorf(
+			return nil, nil, fmt.Errorf(
 				"unsupported resource type in template: %s",
 				unstructuredObj.GroupVersionKind(),
 			)
 		}
 	}
-	return resources, nil
+	if err := applyPatches(pds.Spec.Patches, resources, excluded); err != nil {
+		return nil, nil, err
+	}
+	return resources, excluded, nil
+}
+
+// Process the templates in the given resource of the given type using the
+// given data, according to the templating mode of the given template spec,
+// and set the resource owner
+func renderResource(
+	srt resourceType,
+	resource *unstructured.Unstructured,
+	spec projctlv1beta1.ProjectDevelopmentStreamTemplateSpec,
+	data map[string]any,
+) error {
+	// Remove untouchable fields from the template before processing
+	removeUntouchableFields(resource, srt.untouchableFields)
+
+	// Fields converted to other types are processed first and skipped when
+	// processing string fields, so their rendered values are not processed
+	// again
+	typed := typedFields(spec.TemplatedFields, resource.GetKind())
+	typedPaths := make([][]string, 0, len(typed))
+	for _, field := range typed {
+		if err := applyTypedFieldTemplate(resource.Object, field.Path, field.Type, data); err != nil {
+			return fmt.Errorf("error applying resource template: %s", err)
+		}
+		typedPaths = append(typedPaths, field.Path)
+	}
+
+	var err error
+	allStrings := spec.TemplatingMode == projctlv1beta1.TemplatingModeAllStrings
+	if allStrings {
+		err = applyAllStringsTemplate(resource, typedPaths, data)
+	} else {
+		err = applyResourceTemplate(resource, srt.templateAbleNameFields, data)
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateResourceNameFields(resource, srt.templateAbleNameFields); err != nil {
+		return err
+	}
+	if !allStrings {
+		fields := allowlistedFields(srt, spec.TemplatedFields, resource.GetKind())
+		if err := applyResourceTemplate(resource, fields, data); err != nil {
+			return err
+		}
+	}
+	if srt.ownerNameField != nil {
+		ownerName, ok, err := unstructured.NestedString(resource.Object, srt.ownerNameField...)
+		if ok && err == nil {
+			// If we can't find the owner name field, we just skip
+			// setting an owner
+			ownership.SetWithoutUid(
+				resource,
+				srt.ownerAPI,
+				ownerName,
+				srt.ownerIsController,
+				srt.ownerDeletionBlocked,
+			)
+		}
+	}
+	return nil
+}
+
+// Evaluate the inclusion condition of the given template resource, if any,
+// and remove it from the resource. Returns whether the resource is to be
+// generated, and the condition.
+func evalIncludeIf(resource *unstructured.Unstructured, templateVarValues map[string]any) (bool, string, error) {
+	condition, ok := popAnnotation(resource, projctlv1beta1.IncludeIfAnnotation)
+	if !ok {
+		return true, "", nil
+	}
+	result, err := executeTemplate(condition, templateVarValues)
+	if err != nil {
+		return false, condition, fmt.Errorf(
+			"error evaluating inclusion condition of %s '%s': %s", resource.GetKind(), resource.GetName(), err,
+		)
+	}
+	switch strings.TrimSpace(result) {
+	case "true":
+		return true, condition, nil
+	case "false":
+		return false, condition, nil
+	}
+	return false, condition, fmt.Errorf(
+		"inclusion condition of %s '%s' evaluated to '%s', expected 'true' or 'false'",
+		resource.GetKind(), resource.GetName(), result,
+	)
+}
+
+// Describe the given template resource that was left out because of the
+// given inclusion condition. The name is filled in if it can be generated.
+func excludedResource(
+	resource *unstructured.Unstructured,
+	condition string,
+	templateVarValues map[string]any,
+) projctlv1beta1.ProjectDevelopmentStreamExcludedResource {
+	name, err := executeTemplate(resource.GetName(), templateVarValues)
+	if err != nil {
+		name = ""
+	}
+	return projctlv1beta1.ProjectDevelopmentStreamExcludedResource{
+		APIVersion: resource.GetAPIVersion(),
+		Kind:       resource.GetKind(),
+		Name:       name,
+		Condition:  condition,
+	}
+}
+
+// ResourceAPIs returns the API group/version/kind of each resource type that is
+// currently supported by templates. Only the first API listed for each
+// resource type is returned, so every type appears once.
+func ResourceAPIs() []apischema.GroupVersionKind {
+	rts := resourceTypes()
+	apis := make([]apischema.GroupVersionKind, 0, len(rts))
+	for _, srt := range rts {
+		if len(srt.supportedAPIs) > 0 {
+			apis = append(apis, srt.supportedAPIs[0])
+		}
+	}
+	return apis
+}
+
+// Find the supported resource type for the given GVK, returns nil if the GVK
+// is not supported
+func findResourceType(gvk apischema.GroupVersionKind) *resourceType {
+	rts := resourceTypes()
+	for i := range rts {
+		if findGVK(rts[i].supportedAPIs, gvk) {
+			return &rts[i]
+		}
+	}
+	return nil
 }
 
 func findGVK(GVKs []apischema.GroupVersionKind, someGVK apischema.GroupVersionKind) bool {
@@ -244,7 +390,7 @@ func findGVK(GVKs []apischema.GroupVersionKind, someGVK apischema.GroupVersionKi
 func applyResourceTemplate(
 	resource *unstructured.Unstructured,
 	templateAbleFields [][]string,
-	templateVarValues map[string]string,
+	templateVarValues map[string]any,
 ) error {
 	for _, path := range templateAbleFields {
 		err := applyFieldTemplate(resource.Object, path, templateVarValues)
@@ -255,6 +401,73 @@ func applyResourceTemplate(
 	return nil
 }
 
+// Fields that are never processed as templates
+var nonTemplatableFields = [][]string{{"apiVersion"}, {"kind"}}
+
+// Given a resource and template variable values, treat all the string values
+// in the resource, except for the ones in skipPaths, as text/template
+// templates and execute them generating new values for them
+func applyAllStringsTemplate(
+	resource *unstructured.Unstructured,
+	skipPaths [][]string,
+	templateVarValues map[string]any,
+) error {
+	skipPaths = slices.Concat(nonTemplatableFields, skipPaths)
+	return applyAllStringsFunc(resource.Object, skipPaths, func(path []string, valueTemplate string) (string, bool, error) {
+		value, err := executeTemplate(valueTemplate, templateVarValues)
+		if err != nil {
+			return "", false, fmt.Errorf("error applying resource template in field '%s': %s", strings.Join(path, "."), err)
+		}
+		return value, true, nil
+	})
+}
+
+// Returns the fields, other than name fields, to be processed as templates
+// for a resource of the given kind and type in the Allowlist templating mode
+// and kept as strings
+func allowlistedFields(srt resourceType, templatedFields []projctlv1beta1.TemplatedField, kind string) [][]string {
+	fields := slices.Clone(srt.templateAbleFields)
+	for _, field := range templatedFields {
+		if field.Kind == kind && !isTyped(field) {
+			fields = append(fields, field.Path)
+		}
+	}
+	typed := typedFields(templatedFields, kind)
+	return slices.DeleteFunc(fields, func(path []string) bool {
+		return slices.ContainsFunc(typed, func(field projctlv1beta1.TemplatedField) bool {
+			return slices.Equal(field.Path, path)
+		})
+	})
+}
+
+// Returns the templated fields of a resource of the given kind whose rendered
+// values are converted to types other than string. These are processed in
+// both templating modes.
+func typedFields(templatedFields []projctlv1beta1.TemplatedField, kind string) []projctlv1beta1.TemplatedField {
+	var fields []projctlv1beta1.TemplatedField
+	for _, field := range templatedFields {
+		if field.Kind == kind && isTyped(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Check whether the rendered value of the given templated field is converted
+// to a type other than string
+func isTyped(field projctlv1beta1.TemplatedField) bool {
+	return fieldType(field) != projctlv1beta1.TemplatedFieldTypeString
+}
+
+// Returns the type of the given templated field, taking the default into
+// account
+func fieldType(field projctlv1beta1.TemplatedField) projctlv1beta1.TemplatedFieldType {
+	if field.Type == "" {
+		return projctlv1beta1.TemplatedFieldTypeString
+	}
+	return field.Type
+}
+
 var nameFieldPattern = regexp.MustCompile("^[a-z0-9]([-a-z0-9]*[a-z0-9])?$")
 
 // Given a resource and a list of field paths, check that the value in those
@@ -265,7 +478,7 @@ func validateResourceNameFields(
 	nameFields [][]string,
 ) error {
 	for _, path := range nameFields {
-		err := applyFieldFunc(resource.Object, path, func(value string) (string, bool, error) {
+		err := applyFieldFunc(resource.Object, path, func(value string) (any, bool, error) {
 			if !nameFieldPattern.MatchString(value) {
 				return "", false, fmt.Errorf(
 					"invalid resource name value '%s' for resource field '%s'. "+
@@ -284,34 +497,89 @@ func validateResourceNameFields(
 }
 
 // Get the values for the given template variables using the given values or
-// the defaults if values ar missing
+// the defaults if values are missing, and check them against the variable
+// definitions. Defaults may reference the given built-in context as well as
+// earlier variables. Values are returned the way they are passed to templates,
+// i.e. as strings, or as the structures they describe for yaml variables. All
+// the issues found are returned, joined into a single error.
 func getVarValues(
 	vars []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable,
 	vals []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue,
-) (values map[string]string, err error) {
-	values = map[string]string{}
+	builtins map[string]any,
+) (map[string]any, error) {
+	var errs []error
+	values := map[string]any{}
 	givenValues := map[string]string{}
+	unresolved := map[string]bool{}
+	secret := map[string]bool{}
 	for _, val := range vals {
+		if isUnresolvedValue(val) {
+			unresolved[val.Name] = true
+			// Values from ConfigMaps and Secrets need to be read with
+			// ResolveValues first
+			errs = append(errs, fmt.Errorf(
+				"the value of template variable '%s' is read from a ConfigMap or Secret and was not resolved", val.Name,
+			))
+			continue
+		}
 		givenValues[val.Name] = val.Value
+		secret[val.Name] = isSecretValue(val)
 	}
+	for _, val := range vals {
+		if !slices.ContainsFunc(vars, func(v projctlv1beta1.ProjectDevelopmentStreamTemplateVariable) bool {
+			return v.Name == val.Name
+		}) {
+			errs = append(errs, fmt.Errorf("a value was given for undefined template variable '%s'", val.Name))
+		}
+	}
+	// Variables whose values could not be determined or are invalid. Defaults
+	// referencing them are not computed, so we do not report the same issue
+	// again.
+	failed := map[string]bool{}
 	for _, variable := range vars {
-		if givenValue, ok := givenValues[variable.Name]; ok {
-			values[variable.Name] = givenValue
-		} else if variable.DefaultValue != nil {
-			var value string
-			if value, err = executeTemplate(*variable.DefaultValue, values); err != nil {
-				break
+		var value string
+		givenValue, given := givenValues[variable.Name]
+		switch {
+		case unresolved[variable.Name]:
+			failed[variable.Name] = true
+			continue
+		case given:
+			value = givenValue
+		case variable.Required:
+			errs = append(errs, fmt.Errorf("template variable '%s' is required but no value was given", variable.Name))
+			failed[variable.Name] = true
+			continue
+		case variable.DefaultValue != nil:
+			if referencesAny(*variable.DefaultValue, failed) {
+				failed[variable.Name] = true
+				continue
+			}
+			var err error
+			value, err = executeTemplate(*variable.DefaultValue, templateData(builtins, values))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to compute default value for template variable '%s': %w", variable.Name, err))
+				failed[variable.Name] = true
+				continue
 			}
-			values[variable.Name] = value
-		} else {
-			err = fmt.Errorf(
+		default:
+			errs = append(errs, fmt.Errorf(
 				"template variable '%s' is missing a value and default not defined",
 				variable.Name,
-			)
-			break
+			))
+			failed[variable.Name] = true
+			continue
+		}
+		if err := checkVarValue(variable, value, secret[variable.Name]); err != nil {
+			errs = append(errs, err)
+			failed[variable.Name] = true
+			continue
 		}
+		values[variable.Name] = templateValue(variable, value)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
-	return
+	return values, nil
 }
 
 // removeUntouchableFields removes the specified fields from the resource object.
@@ -325,7 +593,7 @@ func removeUntouchableFields(resource *unstructured.Unstructured, untouchableFie
 
 // HasCreateOnlyFields checks if the resource has any create only fields.
 func HasCreateOnlyFields(resource *unstructured.Unstructured) bool {
-	for _, srt := range supportedResourceTypes {
+	for _, srt := range resourceTypes() {
 		if findGVK(srt.supportedAPIs, resource.GroupVersionKind()) {
 			for _, fieldPath := range srt.createOnlyFields {
 				if _, ok, _ := unstructured.NestedFieldNoCopy(resource.Object, fieldPath...); ok {
@@ -340,7 +608,7 @@ func HasCreateOnlyFields(resource *unstructured.Unstructured) bool {
 
 // RemoveCreateOnlyFields removes the create only fields from the resource object.
 func RemoveCreateOnlyFields(resource *unstructured.Unstructured) {
-	for _, srt := range supportedResourceTypes {
+	for _, srt := range resourceTypes() {
 		if findGVK(srt.supportedAPIs, resource.GroupVersionKind()) {
 			for _, fieldPath := range srt.createOnlyFields {
 				unstructured.RemoveNestedField(resource.Object, fieldPath...)
@@ -353,7 +621,7 @@ func RemoveCreateOnlyFields(resource *unstructured.Unstructured) {
 // GetLiveStateConditionalFields returns the list of fields that should only be included
 // if they exist in the live resource. Returns nil if the resource type doesn't have any.
 func GetLiveStateConditionalFields(resource *unstructured.Unstructured) [][]string {
-	for _, srt := range supportedResourceTypes {
+	for _, srt := range resourceTypes() {
 		if findGVK(srt.supportedAPIs, resource.GroupVersionKind()) {
 			return srt.liveStateConditionalFields
 		}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apischema "k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -14,6 +15,7 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
 
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
 	"github.com/konflux-ci/project-controller/pkg/testhelpers"
 )
 
@@ -146,3 +148,402 @@ var _ = Describe("Resources", func() {
 		)
 	})
 })
+
+var _ = Describe("getVarValues", func() {
+	vars := []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+		{Name: "version"},
+		{Name: "versionName", DefaultValue: new("{{hyphenize .version}}")},
+	}
+
+	DescribeTable(
+		"it calculates variable values from given values and defaults",
+		func(vals []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue, expected map[string]any) {
+			Expect(getVarValues(vars, vals, nil)).To(Equal(expected))
+		},
+		Entry(
+			"using defaults for missing values",
+			[]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{{Name: "version", Value: "1.0.0"}},
+			map[string]any{"version": "1.0.0", "versionName": "1-0-0"},
+		),
+		Entry(
+			"using given values over defaults",
+			[]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+				{Name: "version", Value: "1.0.0"},
+				{Name: "versionName", Value: "one"},
+			},
+			map[string]any{"version": "1.0.0", "versionName": "one"},
+		),
+	)
+
+	DescribeTable(
+		"it reports bad values",
+		func(vals []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue, expected string) {
+			_, err := getVarValues(vars, vals, nil)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry(
+			"missing values for variables without defaults",
+			nil,
+			"template variable 'version' is missing a value and default not defined",
+		),
+		Entry(
+			"values for undefined variables",
+			[]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+				{Name: "version", Value: "1.0.0"},
+				{Name: "nosuchvar", Value: "foo"},
+			},
+			"a value was given for undefined template variable 'nosuchvar'",
+		),
+		Entry(
+			"values read from ConfigMaps or Secrets that were not resolved",
+			[]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{{
+				Name:      "version",
+				ValueFrom: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource{},
+			}},
+			"the value of template variable 'version' is read from a ConfigMap or Secret and was not resolved",
+		),
+	)
+
+	It("lets defaults reference the built-in context", func() {
+		vars := []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			{Name: "appName", DefaultValue: new("{{.project.name}}-{{.stream.name}}")},
+		}
+		pds := projctlv1beta1.ProjectDevelopmentStream{
+			ObjectMeta: metav1.ObjectMeta{Name: "v1"},
+			Spec:       projctlv1beta1.ProjectDevelopmentStreamSpec{Project: "my-project"},
+		}
+		Expect(getVarValues(vars, nil, builtinContext(&pds, nil))).To(Equal(map[string]any{
+			"appName": "my-project-v1",
+		}))
+	})
+
+	It("passes structured values of yaml variables", func() {
+		vars := []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			{Name: "components", Type: projctlv1beta1.VariableTypeYAML},
+			{Name: "first", DefaultValue: new("{{(index .components 0).name}}")},
+		}
+		Expect(getVarValues(vars, []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+			{Name: "components", Value: "[{name: comp1}, {name: comp2}]"},
+		}, nil)).To(Equal(map[string]any{
+			"components": []any{map[string]any{"name": "comp1"}, map[string]any{"name": "comp2"}},
+			"first":      "comp1",
+		}))
+	})
+
+	It("renders large integers of yaml variables in full", func() {
+		vars := []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			{Name: "limits", Type: projctlv1beta1.VariableTypeYAML},
+			{Name: "quota", DefaultValue: new("{{.limits.quota}}")},
+		}
+		Expect(getVarValues(vars, []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+			{Name: "limits", Value: "{quota: 100000000}"},
+		}, nil)).To(HaveKeyWithValue("quota", "100000000"))
+	})
+
+	It("reports all the issues found at once", func() {
+		vars := []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			{Name: "version", Type: projctlv1beta1.VariableTypeSemver},
+			{Name: "versionName", DefaultValue: new("{{hyphenize .version}}")},
+			{Name: "arch", DefaultValue: new("s390x"), Enum: []string{"amd64", "arm64"}},
+			{Name: "branch", DefaultValue: new("main"), Required: true},
+			{Name: "release"},
+		}
+		_, err := getVarValues(vars, []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+			{Name: "version", Value: "v1.2"},
+			{Name: "nosuchvar", Value: "foo"},
+		}, nil)
+		Expect(err).To(MatchError(strings.Join([]string{
+			"a value was given for undefined template variable 'nosuchvar'",
+			"template variable 'version' value 'v1.2' is not a semantic version (e.g. 1.2.3)",
+			"template variable 'arch' value 's390x' is not one of: amd64, arm64",
+			"template variable 'branch' is required but no value was given",
+			"template variable 'release' is missing a value and default not defined",
+		}, "\n")))
+	})
+})
+
+var _ = Describe("MkResources templating modes", func() {
+	var pds projctlv1beta1.ProjectDevelopmentStream
+	var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+
+	BeforeEach(func() {
+		pds = projctlv1beta1.ProjectDevelopmentStream{
+			Spec: projctlv1beta1.ProjectDevelopmentStreamSpec{
+				Template: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+					Values: []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+						{Name: "version", Value: "1.0.0"},
+					},
+				},
+			},
+		}
+		pdst = projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+				Variables: []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{{Name: "version"}},
+				Resources: []projctlv1beta1.UnstructuredObj{
+					{Unstructured: unstructured.Unstructured{Object: map[string]any{
+						"apiVersion": "appstudio.redhat.com/v1alpha1",
+						"kind":       "Application",
+						"metadata": map[string]any{
+							"name": "app-{{hyphenize .version}}",
+							"annotations": map[string]any{
+								"example.com/version": "{{.version}}",
+							},
+						},
+						"spec": map[string]any{
+							"displayName": "App {{.version}}",
+						},
+					}}},
+				},
+			},
+		}
+	})
+
+	It("only templates allowlisted fields by default", func() {
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(1))
+		Expect(resources[0].GetName()).To(Equal("app-1-0-0"))
+		Expect(resources[0].GetAnnotations()).To(HaveKeyWithValue("example.com/version", "{{.version}}"))
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "App 1.0.0")))
+	})
+
+	It("passes list variables to templates as lists", func() {
+		pdst.Spec.Variables = append(pdst.Spec.Variables, projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "archs", Type: projctlv1beta1.VariableTypeList, DefaultValue: new("amd64, arm64"),
+		})
+		pdst.Spec.Resources[0].Object["spec"] = map[string]any{
+			"displayName": `{{range $i, $arch := .archs}}{{if $i}} {{end}}[{{$arch}}]{{end}} ({{join "/" .archs}})`,
+		}
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].Object).To(HaveKeyWithValue(
+			"spec", HaveKeyWithValue("displayName", "[amd64] [arm64] (amd64/arm64)"),
+		))
+	})
+
+	It("templates fields declared by the template", func() {
+		pdst.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Application", Path: []string{"metadata", "annotations", "example.com/version"}},
+		}
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].GetAnnotations()).To(HaveKeyWithValue("example.com/version", "1.0.0"))
+	})
+
+	It("ignores fields declared for other kinds", func() {
+		pdst.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Component", Path: []string{"metadata", "annotations", "example.com/version"}},
+		}
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].GetAnnotations()).To(HaveKeyWithValue("example.com/version", "{{.version}}"))
+	})
+
+	It("converts typed fields to their types in both templating modes", func() {
+		spec := pdst.Spec.Resources[0].Object["spec"].(map[string]any)
+		spec["replicas"] = "{{semverMajor .version}}"
+		spec["settings"] = "{enabled: true, name: 'App {{.version}}'}"
+		pdst.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Application", Path: []string{"spec", "replicas"}, Type: projctlv1beta1.TemplatedFieldTypeInteger},
+			{Kind: "Application", Path: []string{"spec", "settings"}, Type: projctlv1beta1.TemplatedFieldTypeYAML},
+		}
+		expected := map[string]any{
+			"displayName": "App 1.0.0",
+			"replicas":    int64(1),
+			"settings":    map[string]any{"enabled": true, "name": "App 1.0.0"},
+		}
+
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", expected))
+
+		By("Not processing typed field values again in the AllStrings mode")
+		pdst.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllStrings
+		pdst.Spec.Resources[0].SetName("app")
+		spec["displayName"] = "App"
+		spec["replicas"] = "1"
+		pds.Spec.Template.Values[0].Value = "{{.x}}"
+		expected = map[string]any{
+			"displayName": "App",
+			"replicas":    int64(1),
+			"settings":    map[string]any{"enabled": true, "name": "App {{.x}}"},
+		}
+		resources, _, err = MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", expected))
+	})
+
+	It("reports typed fields whose values cannot be converted", func() {
+		pdst.Spec.Resources[0].Object["spec"].(map[string]any)["replicas"] = "{{.version}}"
+		pdst.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Application", Path: []string{"spec", "replicas"}, Type: projctlv1beta1.TemplatedFieldTypeInteger},
+		}
+		_, _, err := MkResources(pds, pdst, nil)
+		Expect(err).To(MatchError(
+			"error applying resource template: field 'spec.replicas' did not render to a valid integer: invalid syntax",
+		))
+	})
+
+	It("templates all strings in the AllStrings mode", func() {
+		pdst.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllStrings
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].GetName()).To(Equal("app-1-0-0"))
+		Expect(resources[0].GetAnnotations()).To(HaveKeyWithValue("example.com/version", "1.0.0"))
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "App 1.0.0")))
+	})
+
+	It("makes the stream and project available to templates", func() {
+		pds.Name = "my-stream"
+		pds.Namespace = "my-ns"
+		pds.Labels = map[string]string{"team": "core"}
+		pds.Spec.Project = "my-project"
+		project := &projctlv1beta1.Project{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-project", Namespace: "my-ns"},
+			Spec:       projctlv1beta1.ProjectSpec{DisplayName: "My Project"},
+		}
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object,
+			`{{.project.displayName}} {{.version}} ({{.stream.namespace}}/{{.stream.name}}, {{index .stream.labels "team"}})`,
+			"spec", "displayName",
+		)).To(Succeed())
+		resources, _, err := MkResources(pds, pdst, project)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue(
+			"displayName", "My Project 1.0.0 (my-ns/my-stream, core)",
+		)))
+	})
+
+	It("lets template variables take precedence over the built-in context", func() {
+		pds.Spec.Project = "my-project"
+		pdst.Spec.Variables = append(pdst.Spec.Variables, projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "project", DefaultValue: new("other-project"),
+		})
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object, "{{.project}}", "spec", "displayName",
+		)).To(Succeed())
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("displayName", "other-project")))
+	})
+
+	DescribeTable(
+		"generates resources according to their inclusion conditions",
+		func(mode projctlv1beta1.TemplatingMode, condition string, included bool) {
+			pdst.Spec.TemplatingMode = mode
+			pdst.Spec.Resources[0].SetAnnotations(map[string]string{
+				projctlv1beta1.IncludeIfAnnotation: condition,
+			})
+			resources, excluded, err := MkResources(pds, pdst, nil)
+			Expect(err).NotTo(HaveOccurred())
+			if included {
+				Expect(resources).To(HaveLen(1))
+				Expect(resources[0].GetAnnotations()).NotTo(HaveKey(projctlv1beta1.IncludeIfAnnotation))
+				Expect(excluded).To(BeEmpty())
+			} else {
+				Expect(resources).To(BeEmpty())
+				Expect(excluded).To(ConsistOf(projctlv1beta1.ProjectDevelopmentStreamExcludedResource{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Application",
+					Name:       "app-1-0-0",
+					Condition:  condition,
+				}))
+			}
+		},
+		Entry("a true condition", projctlv1beta1.TemplatingModeAllowlist, `{{eq .version "1.0.0"}}`, true),
+		Entry("a false condition", projctlv1beta1.TemplatingModeAllowlist, `{{ne .version "1.0.0"}}`, false),
+		Entry("a condition with spaces", projctlv1beta1.TemplatingModeAllowlist, " {{ge (semverMajor .version) 2}}\n", false),
+		Entry("a true condition in the AllStrings mode", projctlv1beta1.TemplatingModeAllStrings, "true", true),
+		Entry("a false condition in the AllStrings mode", projctlv1beta1.TemplatingModeAllStrings, "false", false),
+	)
+
+	It("reports inclusion conditions that are not booleans", func() {
+		pdst.Spec.Resources[0].SetAnnotations(map[string]string{
+			projctlv1beta1.IncludeIfAnnotation: "{{.version}}",
+		})
+		_, _, err := MkResources(pds, pdst, nil)
+		Expect(err).To(MatchError(
+			"inclusion condition of Application 'app-{{hyphenize .version}}' evaluated to '1.0.0', expected 'true' or 'false'",
+		))
+	})
+
+	It("repeats resources for each item of a variable", func() {
+		pds.Spec.Template.Values = append(pds.Spec.Template.Values, projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+			Name: "components", Value: "[{name: comp1, context: ./one}, {name: comp2, context: ./two}, {name: docs}]",
+		})
+		pdst.Spec.Variables = append(pdst.Spec.Variables, projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "components", Type: projctlv1beta1.VariableTypeYAML,
+		})
+		pdst.Spec.Resources = append(pdst.Spec.Resources, projctlv1beta1.UnstructuredObj{
+			Unstructured: unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "appstudio.redhat.com/v1alpha1",
+				"kind":       "Component",
+				"metadata": map[string]any{
+					"name": "{{.item.name}}-{{hyphenize .version}}",
+					"annotations": map[string]any{
+						projctlv1beta1.ForEachAnnotation:   "components",
+						projctlv1beta1.IncludeIfAnnotation: "{{ne .item.name \"docs\"}}",
+					},
+				},
+				"spec": map[string]any{
+					"application":   "app-{{hyphenize .version}}",
+					"componentName": "{{.item.name}}-{{hyphenize .version}}",
+					"source": map[string]any{"git": map[string]any{
+						"context":  "{{.item.context}}",
+						"revision": "{{.itemIndex}}",
+					}},
+				},
+			}},
+		})
+		resources, excluded, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(3))
+		Expect(resources[1].GetName()).To(Equal("comp1-1-0-0"))
+		Expect(resources[1].GetAnnotations()).To(BeEmpty())
+		Expect(resources[1].GetOwnerReferences()).To(ConsistOf(HaveField("Name", "app-1-0-0")))
+		Expect(resources[1].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("source", HaveKeyWithValue(
+			"git", And(HaveKeyWithValue("context", "./one"), HaveKeyWithValue("revision", "0")),
+		))))
+		Expect(resources[2].GetName()).To(Equal("comp2-1-0-0"))
+		Expect(resources[2].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("source", HaveKeyWithValue(
+			"git", HaveKeyWithValue("context", "./two"),
+		))))
+		Expect(excluded).To(ConsistOf(HaveField("Name", "docs-1-0-0")))
+	})
+
+	It("reports resources repeated with the same name", func() {
+		pdst.Spec.Variables = append(pdst.Spec.Variables, projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "archs", Type: projctlv1beta1.VariableTypeList, DefaultValue: new("amd64,arm64"),
+		})
+		pdst.Spec.Resources[0].SetAnnotations(map[string]string{projctlv1beta1.ForEachAnnotation: "archs"})
+		_, _, err := MkResources(pds, pdst, nil)
+		Expect(err).To(MatchError(
+			"Application 'app-1-0-0' is generated more than once by the projctl.konflux.dev/for-each annotation, " +
+				"consider using .item in its name",
+		))
+	})
+
+	It("applies the stream patches to the generated resources without templating them", func() {
+		pds.Spec.Patches = []projctlv1beta1.ProjectDevelopmentStreamPatch{{
+			Target: projctlv1beta1.ProjectDevelopmentStreamPatchTarget{Kind: "Application", Name: "app-1-0-0"},
+			Patch:  `{"metadata": {"annotations": {"example.com/patched": "{{.version}}"}}, "spec": {"extra": "x"}}`,
+		}}
+		resources, _, err := MkResources(pds, pdst, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources[0].GetAnnotations()).To(HaveKeyWithValue("example.com/patched", "{{.version}}"))
+		Expect(resources[0].Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("extra", "x")))
+
+		pds.Spec.Patches[0].Target.Name = "app-{{hyphenize .version}}"
+		_, _, err = MkResources(pds, pdst, nil)
+		Expect(err).To(MatchError(ContainSubstring("which is not generated from the template")))
+	})
+
+	It("still validates name fields in the AllStrings mode", func() {
+		pdst.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllStrings
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object, "app-{{.version}}", "metadata", "name",
+		)).To(Succeed())
+		_, _, err := MkResources(pds, pdst, nil)
+		Expect(err).To(MatchError(ContainSubstring("metadata.name")))
+	})
+})
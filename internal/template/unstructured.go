@@ -1,28 +1,110 @@
 package template
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
+	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
 )
 
 // A function type for applying changes to string fields. Accepts the field
-// current value as a string and returns a new value, a boolean indicating if to
-// apply the new value to the original object and an error value that should be
-// returned from the calling function if not nil
-type fieldFunc func(string) (string, bool, error)
+// current value as a string and returns a new value, which may be of any type
+// the unstructured helpers accept, a boolean indicating if to apply the new
+// value to the original object and an error value that should be returned from
+// the calling function if not nil
+type fieldFunc func(string) (any, bool, error)
 
 // Given a possibly nested map structure, navigate to a particular scalar value
 // using path - a list of string keys. Then treat that value as a template and
 // apply it in-place while using the provided values.
-func applyFieldTemplate(obj map[string]any, path []string, values map[string]string) error {
-	return applyFieldFunc(obj, path, func(valueTemplate string) (string, bool, error) {
+func applyFieldTemplate(obj map[string]any, path []string, values map[string]any) error {
+	return applyFieldFunc(obj, path, func(valueTemplate string) (any, bool, error) {
 		value, err := executeTemplate(valueTemplate, values)
 		return value, true, err
 	})
 }
 
+// Like applyFieldTemplate, but converts the rendered value to the given type
+func applyTypedFieldTemplate(
+	obj map[string]any,
+	path []string,
+	fieldType projctlv1beta1.TemplatedFieldType,
+	values map[string]any,
+) error {
+	return applyFieldFunc(obj, path, func(valueTemplate string) (any, bool, error) {
+		value, err := executeTemplate(valueTemplate, values)
+		if err != nil {
+			return nil, false, err
+		}
+		converted, err := convertFieldValue(value, fieldType)
+		if err != nil {
+			// The rendered value may include values read from Secrets, so it
+			// is left out
+			return nil, false, fmt.Errorf(
+				"field '%s' did not render to a valid %s: %w", strings.Join(path, "."), fieldType, err,
+			)
+		}
+		return converted, true, nil
+	})
+}
+
+// Convert the given rendered field value to the given type. Surrounding
+// whitespace is ignored for all types but strings.
+func convertFieldValue(value string, fieldType projctlv1beta1.TemplatedFieldType) (any, error) {
+	switch fieldType {
+	case projctlv1beta1.TemplatedFieldTypeBoolean:
+		switch strings.TrimSpace(value) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, errors.New("expected 'true' or 'false'")
+	case projctlv1beta1.TemplatedFieldTypeInteger:
+		converted, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		return converted, numError(err)
+	case projctlv1beta1.TemplatedFieldTypeNumber:
+		converted, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return converted, numError(err)
+	case projctlv1beta1.TemplatedFieldTypeYAML:
+		return parseYAMLValue(value)
+	}
+	return value, nil
+}
+
+// Parse the given YAML document into a value made of the types unstructured
+// objects are made of, so that numbers are int64 or float64 and integers get
+// rendered in full
+func parseYAMLValue(value string) (any, error) {
+	valueJSON, err := yaml.YAMLToJSON([]byte(value))
+	if err != nil {
+		return nil, err
+	}
+	var parsed any
+	if err := utiljson.Unmarshal(valueJSON, &parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// Strip the function name and input from number parsing errors, as the input
+// is already reported along with them
+func numError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
 func applyFieldFunc(obj map[string]any, path []string, ff fieldFunc) error {
 	if ind := slices.Index(path, "[]"); ind != -1 {
 		if ind == len(path)-1 {
@@ -65,7 +147,7 @@ func applySliceFieldFunc(obj map[string]any, path []string, ff fieldFunc) error
 		// If the path is not found in the structure, we ignore it
 		return nil
 	}
-	valueArr := make([]string, len(exValArr))
+	valueArr := make([]any, len(exValArr))
 	var setAny bool
 	for i, existingValue := range exValArr {
 		value, set, err := ff(existingValue)
@@ -80,7 +162,7 @@ func applySliceFieldFunc(obj map[string]any, path []string, ff fieldFunc) error
 		setAny = setAny || set
 	}
 	if setAny {
-		err = unstructured.SetNestedStringSlice(obj, valueArr, path...)
+		err = unstructured.SetNestedSlice(obj, valueArr, path...)
 		if err != nil {
 			return fmt.Errorf("error updating object: %s", err)
 		}
@@ -112,3 +194,65 @@ func processNestedSlice(obj map[string]any, path []string, ff fieldFunc) error {
 
 	return nil
 }
+
+// A function type for applying changes to string fields found anywhere in an
+// object. Like fieldFunc but also accepts the path to the field, where list
+// items are represented by "[]"
+type pathFieldFunc func(path []string, value string) (string, bool, error)
+
+// Apply the given function to every string value in the given possibly
+// nested map structure, including strings in nested lists, except for the
+// values found in skipPaths. Values are updated in-place.
+func applyAllStringsFunc(obj map[string]any, skipPaths [][]string, ff pathFieldFunc) error {
+	_, _, err := applyAnyFunc(obj, nil, skipPaths, ff)
+	return err
+}
+
+func applyAnyFunc(value any, path []string, skipPaths [][]string, ff pathFieldFunc) (any, bool, error) {
+	if slices.ContainsFunc(skipPaths, func(skipPath []string) bool { return slices.Equal(skipPath, path) }) {
+		return value, false, nil
+	}
+	switch typedValue := value.(type) {
+	case string:
+		return ff(path, typedValue)
+	case map[string]any:
+		// Go over keys in a stable order so errors are reported consistently
+		for _, key := range slices.Sorted(maps.Keys(typedValue)) {
+			newItem, set, err := applyAnyFunc(typedValue[key], append(slices.Clip(path), key), skipPaths, ff)
+			if err != nil {
+				return nil, false, err
+			}
+			if set {
+				typedValue[key] = newItem
+			}
+		}
+	case []any:
+		for i, item := range typedValue {
+			newItem, set, err := applyAnyFunc(item, append(slices.Clip(path), "[]"), skipPaths, ff)
+			if err != nil {
+				return nil, false, err
+			}
+			if set {
+				typedValue[i] = newItem
+			}
+		}
+	}
+	return value, false, nil
+}
+
+// Remove the given annotation from the given resource, if present. Returns
+// its value and whether it was present.
+func popAnnotation(resource *unstructured.Unstructured, key string) (string, bool) {
+	annotations := resource.GetAnnotations()
+	value, ok := annotations[key]
+	if !ok {
+		return "", false
+	}
+	delete(annotations, key)
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(resource.Object, "metadata", "annotations")
+	} else {
+		resource.SetAnnotations(annotations)
+	}
+	return value, true
+}
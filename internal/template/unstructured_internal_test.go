@@ -1,18 +1,23 @@
 package template
 
 import (
+	"fmt"
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
 )
 
-var someValues = map[string]string{
+var someValues = map[string]any{
 	"foo": "bar",
 	"baz": "bal",
 }
 
 var _ = DescribeTable(
 	"applyFieldTemplate applies a template in a field within a nested structure",
-	func(obj map[string]any, path []string, values map[string]string, expected map[string]any) {
+	func(obj map[string]any, path []string, values map[string]any, expected map[string]any) {
 		err := applyFieldTemplate(obj, path, values)
 
 		Expect(err).NotTo(HaveOccurred())
@@ -196,3 +201,93 @@ var _ = DescribeTable(
 		},
 	),
 )
+
+var _ = Describe("applyAllStringsFunc", func() {
+	It("applies the function to all strings except the skipped paths", func() {
+		obj := map[string]any{
+			"apiVersion": "{{.foo}}",
+			"key1":       "{{.foo}}",
+			"key2": map[string]any{
+				"key2a": []any{"{{.baz}}", int64(3), map[string]any{"key2b": "{{.foo}}"}},
+				"key2c": true,
+			},
+		}
+		var paths []string
+		err := applyAllStringsFunc(obj, [][]string{{"apiVersion"}}, func(path []string, value string) (string, bool, error) {
+			paths = append(paths, strings.Join(path, "."))
+			newValue, err := executeTemplate(value, someValues)
+			return newValue, true, err
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(Equal([]string{"key1", "key2.key2a.[]", "key2.key2a.[].key2b"}))
+		Expect(obj).To(Equal(map[string]any{
+			"apiVersion": "{{.foo}}",
+			"key1":       "bar",
+			"key2": map[string]any{
+				"key2a": []any{"bal", int64(3), map[string]any{"key2b": "bar"}},
+				"key2c": true,
+			},
+		}))
+	})
+
+	It("stops on the first error", func() {
+		obj := map[string]any{"key1": "{{.foo}}", "key2": "{{.baz}}"}
+		err := applyAllStringsFunc(obj, nil, func(path []string, value string) (string, bool, error) {
+			return "", false, fmt.Errorf("failed at %s", strings.Join(path, "."))
+		})
+
+		Expect(err).To(MatchError("failed at key1"))
+	})
+})
+
+var _ = DescribeTable(
+	"applyTypedFieldTemplate converts the rendered value to the field type",
+	func(fieldType projctlv1beta1.TemplatedFieldType, valueTemplate string, expected any) {
+		obj := map[string]any{"key1": valueTemplate, "key2": []any{valueTemplate, valueTemplate}}
+		values := map[string]any{"on": "true", "count": "3", "params": []any{map[string]any{"name": "a"}}}
+
+		Expect(applyTypedFieldTemplate(obj, []string{"key1"}, fieldType, values)).To(Succeed())
+		Expect(applyTypedFieldTemplate(obj, []string{"key2", "[]"}, fieldType, values)).To(Succeed())
+		Expect(obj).To(Equal(map[string]any{"key1": expected, "key2": []any{expected, expected}}))
+	},
+	Entry("for booleans", projctlv1beta1.TemplatedFieldTypeBoolean, "{{.on}}", true),
+	Entry("for integers", projctlv1beta1.TemplatedFieldTypeInteger, " {{.count}}0\n", int64(30)),
+	Entry("for numbers", projctlv1beta1.TemplatedFieldTypeNumber, "{{.count}}.5", 3.5),
+	Entry("for strings", projctlv1beta1.TemplatedFieldTypeString, " {{.count}} ", " 3 "),
+	Entry(
+		"for YAML documents",
+		projctlv1beta1.TemplatedFieldTypeYAML,
+		"{timeout: {{.count}}, enabled: {{.on}}, params: {{toJson .params}}}",
+		map[string]any{"timeout": int64(3), "enabled": true, "params": []any{map[string]any{"name": "a"}}},
+	),
+)
+
+var _ = DescribeTable(
+	"applyTypedFieldTemplate reports values that cannot be converted",
+	func(fieldType projctlv1beta1.TemplatedFieldType, valueTemplate string, expected any) {
+		obj := map[string]any{"key1": map[string]any{"key1a": valueTemplate}}
+		err := applyTypedFieldTemplate(obj, []string{"key1", "key1a"}, fieldType, someValues)
+		Expect(err).To(MatchError(expected))
+	},
+	Entry(
+		"for booleans",
+		projctlv1beta1.TemplatedFieldTypeBoolean, "{{.foo}}",
+		"field 'key1.key1a' did not render to a valid boolean: expected 'true' or 'false'",
+	),
+	Entry(
+		"for integers",
+		projctlv1beta1.TemplatedFieldTypeInteger, "1.5",
+		"field 'key1.key1a' did not render to a valid integer: invalid syntax",
+	),
+	Entry(
+		"for numbers",
+		projctlv1beta1.TemplatedFieldTypeNumber, "{{.baz}}",
+		"field 'key1.key1a' did not render to a valid number: invalid syntax",
+	),
+	Entry(
+		"for YAML documents",
+		projctlv1beta1.TemplatedFieldTypeYAML, "{a: [{{.foo}}}",
+		ContainSubstring("field 'key1.key1a' did not render to a valid yaml: "),
+	),
+)
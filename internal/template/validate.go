@@ -0,0 +1,185 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// Issues reported by Validate that do not prevent generating resources from a
+// template. Undefined variable references are replaced with "<no value>" and
+// the last definition of a duplicate variable is the one that takes effect.
+var (
+	ErrUndefinedVariable = errors.New("reference to undefined variable")
+	ErrDuplicateVariable = errors.New("template variable defined more than once")
+)
+
+// Validate checks the given ProjectDevelopmentStreamTemplate for issues that
+// can be found without having any variable values at hand. It checks that
+// all resource types are supported, that all templated fields, resource
+// inclusion conditions and variable defaults can be parsed and that they only
+// reference variables that are defined (in the case of variable defaults,
+// defined earlier in the variable list) or are part of the built-in stream and
+// project context (or, for resources repeated with the for-each annotation,
+// are the current item), that for-each annotations refer to list or yaml
+// variables, and that variable patterns are valid regular expressions. All the
+// issues found are returned, joined into a single error.
+func Validate(pdst projctlv1beta1.ProjectDevelopmentStreamTemplate) error {
+	var errs []error
+
+	definedVars := map[string]bool{}
+	for _, variable := range pdst.Spec.Variables {
+		if definedVars[variable.Name] {
+			errs = append(errs, fmt.Errorf("%w: '%s'", ErrDuplicateVariable, variable.Name))
+			continue
+		}
+		if variable.DefaultValue != nil {
+			if err := validateTemplateStr(*variable.DefaultValue, definedVars); err != nil {
+				errs = append(errs, fmt.Errorf("invalid default value for template variable '%s': %w", variable.Name, err))
+			}
+		}
+		if variable.Pattern != "" {
+			if _, err := regexp.Compile(variable.Pattern); err != nil {
+				errs = append(errs, fmt.Errorf("invalid pattern for template variable '%s': %w", variable.Name, err))
+			}
+		}
+		definedVars[variable.Name] = true
+	}
+
+	for i, unstructuredObj := range pdst.Spec.Resources {
+		gvk := unstructuredObj.GroupVersionKind()
+		srt := findResourceType(gvk)
+		if srt == nil {
+			errs = append(errs, fmt.Errorf("resource #%d: unsupported resource type in template: %s", i, gvk))
+			continue
+		}
+		// applyFieldFunc may rewrite parts of the object even if no values get
+		// set, so work on a copy
+		resource := unstructuredObj.DeepCopy()
+		// The annotations controlling how the resource is generated are not
+		// fields of the generated resource
+		resourceVars := definedVars
+		if varName, ok := popAnnotation(&resource.Unstructured, projctlv1beta1.ForEachAnnotation); ok {
+			if _, err := forEachVar(&resource.Unstructured, pdst.Spec.Variables, varName); err != nil {
+				errs = append(errs, fmt.Errorf("resource #%d: %w", i, err))
+			}
+			resourceVars = maps.Clone(definedVars)
+			resourceVars[itemKey] = true
+			resourceVars[itemIndexKey] = true
+		}
+		if condition, ok := popAnnotation(&resource.Unstructured, projctlv1beta1.IncludeIfAnnotation); ok {
+			if err := validateTemplateStr(condition, resourceVars); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"resource #%d (%s %s): invalid inclusion condition: %w", i, gvk.Kind, resource.GetName(), err,
+				))
+			}
+		}
+		validateField := func(path []string, value string) (string, bool, error) {
+			if err := validateTemplateStr(value, resourceVars); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"resource #%d (%s %s): invalid template in field '%s': %w",
+					i, gvk.Kind, resource.GetName(), strings.Join(path, "."), err,
+				))
+			}
+			return "", false, nil
+		}
+		if pdst.Spec.TemplatingMode == projctlv1beta1.TemplatingModeAllStrings {
+			_ = applyAllStringsFunc(resource.Object, nonTemplatableFields, validateField)
+			continue
+		}
+		fields := slices.Concat(srt.templateAbleNameFields, allowlistedFields(*srt, pdst.Spec.TemplatedFields, gvk.Kind))
+		for _, field := range typedFields(pdst.Spec.TemplatedFields, gvk.Kind) {
+			fields = append(fields, field.Path)
+		}
+		for _, path := range fields {
+			if err := applyFieldFunc(resource.Object, path, func(value string) (any, bool, error) {
+				return validateField(path, value)
+			}); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"resource #%d (%s %s): invalid field '%s': %w",
+					i, gvk.Kind, resource.GetName(), strings.Join(path, "."), err,
+				))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Parse the given template string and check that it only references the
+// given variables or the built-in context
+func validateTemplateStr(templateStr string, definedVars map[string]bool) error {
+	theTemplate, err := parseTemplate(templateStr)
+	if err != nil {
+		return err
+	}
+	var undefined []string
+	for _, name := range referencedVars(theTemplate.Root) {
+		if !definedVars[name] && !slices.Contains(builtinContextKeys, name) {
+			undefined = append(undefined, name)
+		}
+	}
+	if len(undefined) > 0 {
+		return fmt.Errorf("%w(s): %s", ErrUndefinedVariable, strings.Join(undefined, ", "))
+	}
+	return nil
+}
+
+// Returns the names of the template variables referenced by the given parse
+// tree node, i.e. the top-level fields of the template data that are accessed
+// via {{.name}} or {{$.name}}. Fields accessed within 'range' or 'with' blocks,
+// where '.' is not the template data, are not included.
+func referencedVars(node parse.Node) []string {
+	var names []string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, item := range n.Nodes {
+				walk(item)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			names = append(names, n.Ident[0])
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				names = append(names, n.Ident[1])
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		}
+	}
+	walk(node)
+	return names
+}
@@ -0,0 +1,231 @@
+package template
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("Validate", func() {
+	var pdst projctlv1beta1.ProjectDevelopmentStreamTemplate
+
+	BeforeEach(func() {
+		pdst = projctlv1beta1.ProjectDevelopmentStreamTemplate{
+			Spec: projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{
+				Variables: []projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+					{Name: "version"},
+					{Name: "versionName", DefaultValue: new("{{hyphenize .version}}")},
+				},
+				Resources: []projctlv1beta1.UnstructuredObj{
+					{Unstructured: unstructured.Unstructured{Object: map[string]any{
+						"apiVersion": "appstudio.redhat.com/v1alpha1",
+						"kind":       "Application",
+						"metadata": map[string]any{
+							"name": "app-{{.versionName}}",
+						},
+						"spec": map[string]any{
+							"displayName": "App {{$.version}}",
+						},
+					}}},
+					{Unstructured: unstructured.Unstructured{Object: map[string]any{
+						"apiVersion": "appstudio.redhat.com/v1alpha1",
+						"kind":       "Component",
+						"metadata": map[string]any{
+							"name": "comp-{{.versionName}}",
+							"annotations": map[string]any{
+								// Fields that are not templated are not checked
+								"pvc.konflux.dev/cloned-from": "{{.notAVariable",
+							},
+						},
+						"spec": map[string]any{
+							"application":      "app-{{.versionName}}",
+							"build-nudges-ref": []any{"other-{{.versionName}}", "fixed"},
+							"source": map[string]any{
+								"git": map[string]any{
+									"revision": "{{if .version}}{{.version}}{{else}}main{{end}}",
+								},
+							},
+						},
+					}}},
+				},
+			},
+		}
+	})
+
+	It("accepts a valid template", func() {
+		Expect(Validate(pdst)).To(Succeed())
+	})
+
+	It("ignores fields referenced in range and with blocks", func() {
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object,
+			"App {{with .version}}{{.major}}{{end}}",
+			"spec", "displayName",
+		)).To(Succeed())
+		Expect(Validate(pdst)).To(Succeed())
+	})
+
+	It("accepts references to the built-in stream and project context", func() {
+		pdst.Spec.Variables = append(pdst.Spec.Variables, projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "team", DefaultValue: new(`{{index .stream.labels "team"}}`),
+		})
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object,
+			"{{.project.displayName}} {{.version}} ({{.stream.name}})",
+			"spec", "displayName",
+		)).To(Succeed())
+		Expect(Validate(pdst)).To(Succeed())
+	})
+
+	It("accepts references to the current item in repeated resources", func() {
+		pdst.Spec.Variables = append(pdst.Spec.Variables, projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "components", Type: projctlv1beta1.VariableTypeYAML,
+		})
+		pdst.Spec.Resources[1].SetAnnotations(map[string]string{
+			projctlv1beta1.ForEachAnnotation:   "components",
+			projctlv1beta1.IncludeIfAnnotation: "{{ne .item.name \"docs\"}}",
+		})
+		pdst.Spec.Resources[1].SetName("{{.item.name}}-{{.versionName}}-{{.itemIndex}}")
+		Expect(Validate(pdst)).To(Succeed())
+	})
+
+	It("does not modify the template", func() {
+		original := pdst.DeepCopy()
+		Expect(Validate(pdst)).To(Succeed())
+		Expect(pdst).To(Equal(*original))
+	})
+
+	It("checks fields declared by the template", func() {
+		pdst.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{
+			{Kind: "Component", Path: []string{"metadata", "annotations", "pvc.konflux.dev/cloned-from"}},
+		}
+		Expect(Validate(pdst)).To(MatchError(ContainSubstring(
+			"resource #1 (Component comp-{{.versionName}}): invalid template in field " +
+				"'metadata.annotations.pvc.konflux.dev/cloned-from'",
+		)))
+	})
+
+	It("checks typed fields declared by the template", func() {
+		pdst.Spec.TemplatedFields = []projctlv1beta1.TemplatedField{{
+			Kind: "Component",
+			Path: []string{"metadata", "annotations", "pvc.konflux.dev/cloned-from"},
+			Type: projctlv1beta1.TemplatedFieldTypeYAML,
+		}}
+		Expect(Validate(pdst)).To(MatchError(ContainSubstring(
+			"resource #1 (Component comp-{{.versionName}}): invalid template in field " +
+				"'metadata.annotations.pvc.konflux.dev/cloned-from'",
+		)))
+	})
+
+	It("checks all string fields in the AllStrings mode", func() {
+		pdst.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllStrings
+		Expect(Validate(pdst)).To(MatchError(ContainSubstring(
+			"invalid template in field 'metadata.annotations.pvc.konflux.dev/cloned-from'",
+		)))
+	})
+
+	DescribeTable(
+		"reports issues found in the template",
+		func(modify func(*projctlv1beta1.ProjectDevelopmentStreamTemplate), messages ...string) {
+			modify(&pdst)
+			err := Validate(pdst)
+			Expect(err).To(HaveOccurred())
+			for _, msg := range messages {
+				Expect(err.Error()).To(ContainSubstring(msg))
+			}
+		},
+		Entry(
+			"inclusion conditions referencing undefined variables",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Resources[0].SetAnnotations(map[string]string{
+					projctlv1beta1.IncludeIfAnnotation: `{{eq .stage "prod"}}`,
+				})
+				pdst.Spec.TemplatingMode = projctlv1beta1.TemplatingModeAllStrings
+			},
+			"resource #0 (Application app-{{.versionName}}): invalid inclusion condition: "+
+				"reference to undefined variable(s): stage",
+		),
+		Entry(
+			"references to the current item in resources that are not repeated",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Resources[0].SetName("app-{{.item}}")
+			},
+			"resource #0 (Application app-{{.item}}): invalid template in field 'metadata.name': "+
+				"reference to undefined variable(s): item",
+		),
+		Entry(
+			"for-each annotations referring to variables that are not lists",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Resources[0].SetAnnotations(map[string]string{projctlv1beta1.ForEachAnnotation: "version"})
+			},
+			"resource #0: Application 'app-{{.versionName}}' is repeated for each item of template variable "+
+				"'version', which is not of the list or yaml type",
+		),
+		Entry(
+			"unsupported resource types",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Resources[1].SetKind("Snapshot")
+			},
+			"resource #1: unsupported resource type in template: appstudio.redhat.com/v1alpha1, Kind=Snapshot",
+		),
+		Entry(
+			"templated fields that fail to parse",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				Expect(unstructured.SetNestedField(
+					pdst.Spec.Resources[0].Object, "App {{.version", "spec", "displayName",
+				)).To(Succeed())
+			},
+			"resource #0 (Application app-{{.versionName}}): invalid template in field 'spec.displayName'",
+		),
+		Entry(
+			"templated fields that reference undefined variables",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				Expect(unstructured.SetNestedStringSlice(
+					pdst.Spec.Resources[1].Object,
+					[]string{"other-{{.foo}}-{{.bar}}"},
+					"spec", "build-nudges-ref",
+				)).To(Succeed())
+			},
+			"invalid template in field 'spec.build-nudges-ref.[]': reference to undefined variable(s): foo, bar",
+		),
+		Entry(
+			"defaults that reference later variables",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Variables[0].DefaultValue = new("{{.versionName}}")
+			},
+			"invalid default value for template variable 'version': reference to undefined variable(s): versionName",
+		),
+		Entry(
+			"defaults that fail to parse",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Variables[1].DefaultValue = new("{{nosuchfunc .version}}")
+			},
+			"invalid default value for template variable 'versionName'",
+		),
+		Entry(
+			"duplicate variables",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Variables = append(pdst.Spec.Variables, pdst.Spec.Variables[0])
+			},
+			"template variable defined more than once: 'version'",
+		),
+		Entry(
+			"invalid variable patterns",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Variables[0].Pattern = "^v[0-9"
+			},
+			"invalid pattern for template variable 'version'",
+		),
+		Entry(
+			"multiple issues at once",
+			func(pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate) {
+				pdst.Spec.Resources[1].SetKind("Snapshot")
+				pdst.Spec.Variables[0].DefaultValue = new("{{.versionName}}")
+			},
+			"unsupported resource type in template",
+			"invalid default value for template variable 'version'",
+		),
+	)
+})
@@ -0,0 +1,121 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// ResolveValues returns the template values of the given stream, with the
+// values given via valueFrom read from the ConfigMaps and Secrets they refer
+// to. Values from optional sources that do not exist are left out, so the
+// variable defaults apply to them. Values read from Secrets keep their
+// valueFrom, so that they can be left out of error messages. All the issues
+// found are returned, joined into a single error.
+func ResolveValues(
+	ctx context.Context,
+	c client.Reader,
+	pds *projctlv1beta1.ProjectDevelopmentStream,
+) ([]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue, error) {
+	if pds.Spec.Template == nil {
+		return nil, nil
+	}
+	var errs []error
+	values := make([]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue, 0, len(pds.Spec.Template.Values))
+	for _, val := range pds.Spec.Template.Values {
+		if val.ValueFrom == nil {
+			values = append(values, val)
+			continue
+		}
+		value, ok, err := resolveValueSource(ctx, c, pds.GetNamespace(), val.ValueFrom)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read the value of template variable '%s': %w", val.Name, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		resolved := projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: val.Name, Value: value}
+		if val.ValueFrom.SecretKeyRef != nil && value != "" {
+			resolved.ValueFrom = val.ValueFrom
+		}
+		values = append(values, resolved)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Read the value the given source refers to. Returns false if the source is
+// optional and either it or the selected key does not exist.
+func resolveValueSource(
+	ctx context.Context,
+	c client.Reader,
+	namespace string,
+	source *projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource,
+) (string, bool, error) {
+	switch {
+	case source.ConfigMapKeyRef != nil:
+		ref := source.ConfigMapKeyRef
+		optional := ref.Optional != nil && *ref.Optional
+		var cm corev1.ConfigMap
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, &cm); err != nil {
+			if optional && apierrors.IsNotFound(err) {
+				return "", false, nil
+			}
+			return "", false, err
+		}
+		if value, ok := cm.Data[ref.Key]; ok {
+			return value, true, nil
+		}
+		if value, ok := cm.BinaryData[ref.Key]; ok {
+			return string(value), true, nil
+		}
+		if optional {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("key '%s' not found in ConfigMap '%s'", ref.Key, ref.Name)
+	case source.SecretKeyRef != nil:
+		ref := source.SecretKeyRef
+		optional := ref.Optional != nil && *ref.Optional
+		var secret corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+			if optional && apierrors.IsNotFound(err) {
+				return "", false, nil
+			}
+			return "", false, err
+		}
+		if secret.Labels[projctlv1beta1.TemplateValuesLabel] != "true" {
+			return "", false, fmt.Errorf(
+				"Secret '%s' is not labeled %s=true to allow reading template values from it",
+				ref.Name, projctlv1beta1.TemplateValuesLabel,
+			)
+		}
+		if value, ok := secret.Data[ref.Key]; ok {
+			return string(value), true, nil
+		}
+		if optional {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("key '%s' not found in Secret '%s'", ref.Key, ref.Name)
+	}
+	return "", false, errors.New("no ConfigMap or Secret key selected")
+}
+
+// Check whether the given value is one read from a Secret by ResolveValues.
+// Values that are not resolved yet have no value set.
+func isSecretValue(val projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue) bool {
+	return val.ValueFrom != nil && val.ValueFrom.SecretKeyRef != nil && val.Value != ""
+}
+
+// Check whether the given value still needs to be read with ResolveValues
+func isUnresolvedValue(val projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue) bool {
+	return val.ValueFrom != nil && !isSecretValue(val)
+}
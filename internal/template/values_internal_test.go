@@ -0,0 +1,144 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("ResolveValues", func() {
+	var c client.Reader
+
+	BeforeEach(func() {
+		c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "settings"},
+				Data:       map[string]string{"org": "my-org"},
+				BinaryData: map[string][]byte{"binary": []byte("bin")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "my-ns", Name: "creds",
+					Labels: map[string]string{projctlv1beta1.TemplateValuesLabel: "true"},
+				},
+				Data: map[string][]byte{"token": []byte("s3cr3t"), "empty": nil},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "private"},
+				Data:       map[string][]byte{"token": []byte("s3cr3t")},
+			},
+		).Build()
+	})
+
+	fromConfigMap := func(name, key string, optional bool) *projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource {
+		return &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Key:                  key,
+				Optional:             &optional,
+			},
+		}
+	}
+
+	fromSecret := func(name, key string, optional bool) *projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource {
+		return &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Key:                  key,
+				Optional:             &optional,
+			},
+		}
+	}
+
+	resolve := func(vals ...projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue) (
+		[]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue, error,
+	) {
+		pds := &projctlv1beta1.ProjectDevelopmentStream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-stream"},
+			Spec: projctlv1beta1.ProjectDevelopmentStreamSpec{
+				Template: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{Name: "my-template", Values: vals},
+			},
+		}
+		return ResolveValues(context.Background(), c, pds)
+	}
+
+	It("reads values from ConfigMaps and Secrets", func() {
+		Expect(resolve(
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "version", Value: "1.0.0"},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "org", ValueFrom: fromConfigMap("settings", "org", false)},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "bin", ValueFrom: fromConfigMap("settings", "binary", false)},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "token", ValueFrom: fromSecret("creds", "token", false)},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "empty", ValueFrom: fromSecret("creds", "empty", false)},
+		)).To(Equal([]projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+			{Name: "version", Value: "1.0.0"},
+			{Name: "org", Value: "my-org"},
+			{Name: "bin", Value: "bin"},
+			{Name: "token", Value: "s3cr3t", ValueFrom: fromSecret("creds", "token", false)},
+			{Name: "empty", Value: ""},
+		}))
+	})
+
+	It("only reads values from Secrets that allow it", func() {
+		_, err := resolve(
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "token", ValueFrom: fromSecret("private", "token", true)},
+		)
+		Expect(err).To(MatchError(
+			"failed to read the value of template variable 'token': " +
+				"Secret 'private' is not labeled projctl.konflux.dev/template-values=true " +
+				"to allow reading template values from it",
+		))
+	})
+
+	It("reveals values read from ConfigMaps when they are invalid", func() {
+		values, err := resolve(
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "org", ValueFrom: fromConfigMap("settings", "org", false)},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = getVarValues([]projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			{Name: "org", Type: projctlv1beta1.VariableTypeInteger},
+		}, values, nil)
+		Expect(err).To(MatchError("template variable 'org' value 'my-org' is not an integer"))
+	})
+
+	It("does not reveal values read from Secrets when they are invalid", func() {
+		values, err := resolve(
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "token", ValueFrom: fromSecret("creds", "token", false)},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = getVarValues([]projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			{Name: "token", Type: projctlv1beta1.VariableTypeInteger, Pattern: "^[a-z]+$", Enum: []string{"a"}},
+		}, values, nil)
+		Expect(err).To(MatchError(ContainSubstring("template variable 'token' value does not match pattern")))
+		Expect(err.Error()).NotTo(ContainSubstring("s3cr3t"))
+	})
+
+	It("leaves out values from optional sources that do not exist", func() {
+		Expect(resolve(
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "a", ValueFrom: fromConfigMap("nosuchcm", "org", true)},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "b", ValueFrom: fromConfigMap("settings", "nosuchkey", true)},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "c", ValueFrom: fromSecret("nosuchsecret", "token", true)},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "d", ValueFrom: fromSecret("creds", "nosuchkey", true)},
+		)).To(BeEmpty())
+	})
+
+	It("reports all the sources that cannot be read", func() {
+		_, err := resolve(
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "a", ValueFrom: fromConfigMap("nosuchcm", "org", false)},
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "b", ValueFrom: fromSecret("creds", "nosuchkey", false)},
+		)
+		Expect(err).To(MatchError(And(
+			ContainSubstring(`failed to read the value of template variable 'a': configmaps "nosuchcm" not found`),
+			ContainSubstring("failed to read the value of template variable 'b': key 'nosuchkey' not found in Secret 'creds'"),
+		)))
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
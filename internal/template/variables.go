@@ -0,0 +1,145 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// Semantic version syntax as defined by https://semver.org
+var semverPattern = regexp.MustCompile(
+	`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`,
+)
+
+// Split the value of a list variable into its items
+func listItems(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+// Check the given value against the type, pattern and enum of the given
+// template variable. All the violations found are returned, joined into a
+// single error. The errors end up in the stream status and in events, so
+// secret values are left out of them.
+func checkVarValue(variable projctlv1beta1.ProjectDevelopmentStreamTemplateVariable, value string, secret bool) error {
+	// Returns how to refer to the given value, or to the given list item if
+	// the index is not negative
+	describe := func(value string, index int) string {
+		switch {
+		case !secret:
+			return fmt.Sprintf("value '%s'", value)
+		case index >= 0:
+			return fmt.Sprintf("item #%d", index)
+		}
+		return "value"
+	}
+	var errs []error
+	items := []string{value}
+	switch variable.Type {
+	case projctlv1beta1.VariableTypeInteger:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"template variable '%s' %s is not an integer", variable.Name, describe(value, -1),
+			))
+		}
+	case projctlv1beta1.VariableTypeBoolean:
+		if value != "true" && value != "false" {
+			errs = append(errs, fmt.Errorf(
+				"template variable '%s' %s is not a boolean, expected 'true' or 'false'",
+				variable.Name, describe(value, -1),
+			))
+		}
+	case projctlv1beta1.VariableTypeSemver:
+		if !semverPattern.MatchString(value) {
+			errs = append(errs, fmt.Errorf(
+				"template variable '%s' %s is not a semantic version (e.g. 1.2.3)", variable.Name, describe(value, -1),
+			))
+		}
+	case projctlv1beta1.VariableTypeList:
+		items = listItems(value)
+	case projctlv1beta1.VariableTypeYAML:
+		var parsed any
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+			if secret {
+				// Parse errors may quote the value
+				errs = append(errs, fmt.Errorf("template variable '%s' value is not valid YAML", variable.Name))
+			} else {
+				errs = append(errs, fmt.Errorf("template variable '%s' value is not valid YAML: %w", variable.Name, err))
+			}
+		}
+	}
+
+	var pattern *regexp.Regexp
+	if variable.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(variable.Pattern); err != nil {
+			return errors.Join(append(errs, fmt.Errorf(
+				"template variable '%s' has an invalid pattern: %w", variable.Name, err,
+			))...)
+		}
+	}
+	for i, item := range items {
+		index := -1
+		if variable.Type == projctlv1beta1.VariableTypeList {
+			index = i
+		}
+		if pattern != nil && !pattern.MatchString(item) {
+			errs = append(errs, fmt.Errorf(
+				"template variable '%s' %s does not match pattern '%s'", variable.Name, describe(item, index), variable.Pattern,
+			))
+		}
+		if len(variable.Enum) > 0 && !slices.Contains(variable.Enum, item) {
+			errs = append(errs, fmt.Errorf(
+				"template variable '%s' %s is not one of: %s",
+				variable.Name, describe(item, index), strings.Join(variable.Enum, ", "),
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Returns the value to pass to templates for the given variable, given its
+// value as a string. The value is expected to be checked with checkVarValue.
+// List values are passed as lists of their items, so templates can range
+// over them.
+func templateValue(variable projctlv1beta1.ProjectDevelopmentStreamTemplateVariable, value string) any {
+	switch variable.Type {
+	case projctlv1beta1.VariableTypeList:
+		items := []any{}
+		for _, item := range listItems(value) {
+			items = append(items, item)
+		}
+		return items
+	case projctlv1beta1.VariableTypeYAML:
+		parsed, _ := parseYAMLValue(value)
+		return parsed
+	}
+	return value
+}
+
+// Check whether the given template string references any of the given
+// variables. Templates that fail to parse are assumed not to.
+func referencesAny(templateStr string, vars map[string]bool) bool {
+	theTemplate, err := parseTemplate(templateStr)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(referencedVars(theTemplate.Root), func(name string) bool {
+		return vars[name]
+	})
+}
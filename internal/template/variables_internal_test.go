@@ -0,0 +1,169 @@
+package template
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("checkVarValue", func() {
+	DescribeTable(
+		"it accepts valid values",
+		func(variable projctlv1beta1.ProjectDevelopmentStreamTemplateVariable, value string) {
+			variable.Name = "myvar"
+			Expect(checkVarValue(variable, value, false)).To(Succeed())
+		},
+		Entry("any string", projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{}, "foo bar"),
+		Entry(
+			"integers",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeInteger},
+			"-42",
+		),
+		Entry(
+			"booleans",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeBoolean},
+			"false",
+		),
+		Entry(
+			"semantic versions",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeSemver},
+			"1.2.3-rc.1+build.5",
+		),
+		Entry(
+			"lists with matching items",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+				Type:    projctlv1beta1.VariableTypeList,
+				Pattern: "^[a-z0-9]+$",
+				Enum:    []string{"amd64", "arm", "ppc"},
+			},
+			"amd64, arm",
+		),
+		Entry("empty lists", projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Type: projctlv1beta1.VariableTypeList,
+			Enum: []string{"amd64"},
+		}, ""),
+		Entry(
+			"YAML documents",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeYAML},
+			"- name: comp1\n  context: ./comp1\n",
+		),
+		Entry(
+			"values matching a pattern",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Pattern: `^v\d+\.\d+$`},
+			"v1.2",
+		),
+		Entry(
+			"values from an enum",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Enum: []string{"dev", "prod"}},
+			"prod",
+		),
+	)
+
+	DescribeTable(
+		"it reports invalid values",
+		func(variable projctlv1beta1.ProjectDevelopmentStreamTemplateVariable, value string, expected string) {
+			variable.Name = "myvar"
+			Expect(checkVarValue(variable, value, false)).To(MatchError(expected))
+		},
+		Entry(
+			"non-integers",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeInteger},
+			"1.5",
+			"template variable 'myvar' value '1.5' is not an integer",
+		),
+		Entry(
+			"non-booleans",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeBoolean},
+			"yes",
+			"template variable 'myvar' value 'yes' is not a boolean, expected 'true' or 'false'",
+		),
+		Entry(
+			"incomplete versions",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeSemver},
+			"1.2",
+			"template variable 'myvar' value '1.2' is not a semantic version (e.g. 1.2.3)",
+		),
+		Entry(
+			"prefixed versions",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeSemver},
+			"v1.2.3",
+			"template variable 'myvar' value 'v1.2.3' is not a semantic version (e.g. 1.2.3)",
+		),
+		Entry(
+			"invalid YAML",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Type: projctlv1beta1.VariableTypeYAML},
+			"[comp1",
+			"template variable 'myvar' value is not valid YAML: error converting YAML to JSON: "+
+				"yaml: line 1: did not find expected ',' or ']'",
+		),
+		Entry(
+			"values not matching the pattern",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Pattern: `^v\d+\.\d+$`},
+			"1.2",
+			`template variable 'myvar' value '1.2' does not match pattern '^v\d+\.\d+$'`,
+		),
+		Entry(
+			"values not in the enum",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Enum: []string{"dev", "prod"}},
+			"stage",
+			"template variable 'myvar' value 'stage' is not one of: dev, prod",
+		),
+		Entry(
+			"every bad list item",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+				Type: projctlv1beta1.VariableTypeList,
+				Enum: []string{"amd64", "arm"},
+			},
+			"amd64,s390x,ppc",
+			"template variable 'myvar' value 's390x' is not one of: amd64, arm\n"+
+				"template variable 'myvar' value 'ppc' is not one of: amd64, arm",
+		),
+		Entry(
+			"invalid patterns",
+			projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Pattern: "^v[0-9"},
+			"v1",
+			"template variable 'myvar' has an invalid pattern: error parsing regexp: missing closing ]: `[0-9`",
+		),
+	)
+
+	It("leaves secret values out of the errors", func() {
+		variable := projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "myvar", Type: projctlv1beta1.VariableTypeList, Enum: []string{"a"},
+		}
+		Expect(checkVarValue(variable, "a,s3cr3t", true)).To(MatchError(
+			"template variable 'myvar' item #1 is not one of: a",
+		))
+		variable = projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{
+			Name: "myvar", Type: projctlv1beta1.VariableTypeYAML, Pattern: "^a$",
+		}
+		Expect(checkVarValue(variable, "s3cr3t: [", true)).To(MatchError(
+			"template variable 'myvar' value is not valid YAML\n" +
+				"template variable 'myvar' value does not match pattern '^a$'",
+		))
+	})
+})
+
+var _ = DescribeTable(
+	"templateValue passes values to templates",
+	func(variableType projctlv1beta1.VariableType, value string, expected any) {
+		variable := projctlv1beta1.ProjectDevelopmentStreamTemplateVariable{Name: "myvar", Type: variableType}
+		Expect(templateValue(variable, value)).To(Equal(expected))
+	},
+	Entry("as strings", projctlv1beta1.VariableTypeInteger, "42", "42"),
+	Entry("as lists of items for lists", projctlv1beta1.VariableTypeList, "a, b", []any{"a", "b"}),
+	Entry("as empty lists for empty lists", projctlv1beta1.VariableTypeList, "", []any{}),
+	Entry(
+		"as structures for YAML",
+		projctlv1beta1.VariableTypeYAML,
+		"- name: comp1\n  replicas: 2\n",
+		[]any{map[string]any{"name": "comp1", "replicas": int64(2)}},
+	),
+	Entry(
+		"with integers kept apart from other numbers",
+		projctlv1beta1.VariableTypeYAML,
+		"{quota: 100000000, ratio: 0.5}",
+		map[string]any{"quota": int64(100000000), "ratio": float64(0.5)},
+	),
+	Entry("as empty lists for YAML", projctlv1beta1.VariableTypeYAML, "[]", []any{}),
+)
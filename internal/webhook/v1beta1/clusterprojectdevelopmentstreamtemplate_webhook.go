@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// SetupClusterProjectDevelopmentStreamTemplateWebhookWithManager registers the
+// webhook for ClusterProjectDevelopmentStreamTemplate in the manager.
+func SetupClusterProjectDevelopmentStreamTemplateWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{}).
+		WithValidator(&ClusterProjectDevelopmentStreamTemplateCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-projctl-konflux-dev-v1beta1-clusterprojectdevelopmentstreamtemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=projctl.konflux.dev,resources=clusterprojectdevelopmentstreamtemplates,verbs=create;update,versions=v1beta1,name=vclusterprojectdevelopmentstreamtemplate-v1beta1.kb.io,admissionReviewVersions=v1
+
+// ClusterProjectDevelopmentStreamTemplateCustomValidator validates cluster
+// templates the same way ProjectDevelopmentStreamTemplateCustomValidator
+// validates namespaced ones.
+type ClusterProjectDevelopmentStreamTemplateCustomValidator struct {
+	// Used for fetching the templates a template extends
+	Client client.Reader
+}
+
+var _ admission.Validator[*projctlv1beta1.ClusterProjectDevelopmentStreamTemplate] = &ClusterProjectDevelopmentStreamTemplateCustomValidator{}
+
+// ValidateCreate implements admission.Validator
+func (v *ClusterProjectDevelopmentStreamTemplateCustomValidator) ValidateCreate(
+	ctx context.Context, cpdst *projctlv1beta1.ClusterProjectDevelopmentStreamTemplate,
+) (admission.Warnings, error) {
+	return validateTemplate(ctx, v.Client, "ClusterProjectDevelopmentStreamTemplate", &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+		ObjectMeta: cpdst.ObjectMeta,
+		Spec:       cpdst.Spec,
+	})
+}
+
+// ValidateUpdate implements admission.Validator
+func (v *ClusterProjectDevelopmentStreamTemplateCustomValidator) ValidateUpdate(
+	ctx context.Context, oldCpdst, cpdst *projctlv1beta1.ClusterProjectDevelopmentStreamTemplate,
+) (admission.Warnings, error) {
+	if equality.Semantic.DeepEqual(oldCpdst.Spec, cpdst.Spec) {
+		return nil, nil
+	}
+	return validateTemplate(ctx, v.Client, "ClusterProjectDevelopmentStreamTemplate", &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+		ObjectMeta: cpdst.ObjectMeta,
+		Spec:       cpdst.Spec,
+	})
+}
+
+// ValidateDelete implements admission.Validator
+func (v *ClusterProjectDevelopmentStreamTemplateCustomValidator) ValidateDelete(
+	ctx context.Context, cpdst *projctlv1beta1.ClusterProjectDevelopmentStreamTemplate,
+) (admission.Warnings, error) {
+	return nil, nil
+}
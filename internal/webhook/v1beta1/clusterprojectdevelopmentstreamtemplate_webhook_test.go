@@ -0,0 +1,63 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("ClusterProjectDevelopmentStreamTemplate Webhook", func() {
+	ctx := context.Background()
+
+	var (
+		validator ClusterProjectDevelopmentStreamTemplateCustomValidator
+		cpdst     *projctlv1beta1.ClusterProjectDevelopmentStreamTemplate
+	)
+
+	BeforeEach(func() {
+		validator = ClusterProjectDevelopmentStreamTemplateCustomValidator{}
+		cpdst = &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{}
+		sampleResource("projctl_v1beta1_clusterprojectdevelopmentstreamtemplate.yaml", cpdst)
+	})
+
+	It("admits a valid template", func() {
+		warnings, err := validator.ValidateCreate(ctx, cpdst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("rejects templates with unsupported resource types", func() {
+		cpdst.Spec.Resources[0].SetKind("Snapshot")
+		_, err := validator.ValidateCreate(ctx, cpdst)
+		Expect(err).To(MatchError(ContainSubstring(
+			"invalid ClusterProjectDevelopmentStreamTemplate: resource #0: unsupported resource type in template",
+		)))
+	})
+
+	It("admits metadata changes to invalid templates", func() {
+		cpdst.Spec.Resources[0].SetKind("Snapshot")
+		oldCpdst := cpdst.DeepCopy()
+		cpdst.SetLabels(map[string]string{"foo": "bar"})
+		_, err := validator.ValidateUpdate(ctx, oldCpdst, cpdst)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
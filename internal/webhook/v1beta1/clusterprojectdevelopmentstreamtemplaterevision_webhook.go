@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// SetupClusterProjectDevelopmentStreamTemplateRevisionWebhookWithManager
+// registers the webhook for ClusterProjectDevelopmentStreamTemplateRevision in
+// the manager.
+func SetupClusterProjectDevelopmentStreamTemplateRevisionWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision{}).
+		WithValidator(&ClusterProjectDevelopmentStreamTemplateRevisionCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-projctl-konflux-dev-v1beta1-clusterprojectdevelopmentstreamtemplaterevision,mutating=false,failurePolicy=fail,sideEffects=None,groups=projctl.konflux.dev,resources=clusterprojectdevelopmentstreamtemplaterevisions,verbs=update,versions=v1beta1,name=vclusterprojectdevelopmentstreamtemplaterevision-v1beta1.kb.io,admissionReviewVersions=v1
+
+// ClusterProjectDevelopmentStreamTemplateRevisionCustomValidator rejects
+// changes to cluster template revisions the same way
+// ProjectDevelopmentStreamTemplateRevisionCustomValidator does for namespaced
+// ones.
+type ClusterProjectDevelopmentStreamTemplateRevisionCustomValidator struct{}
+
+var _ admission.Validator[*projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision] = &ClusterProjectDevelopmentStreamTemplateRevisionCustomValidator{}
+
+// ValidateCreate implements admission.Validator
+func (v *ClusterProjectDevelopmentStreamTemplateRevisionCustomValidator) ValidateCreate(
+	ctx context.Context, cpdstr *projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision,
+) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate implements admission.Validator
+func (v *ClusterProjectDevelopmentStreamTemplateRevisionCustomValidator) ValidateUpdate(
+	ctx context.Context, oldCpdstr, cpdstr *projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision,
+) (admission.Warnings, error) {
+	if oldCpdstr.Revision != cpdstr.Revision ||
+		!equality.Semantic.DeepEqual(oldCpdstr.Bases, cpdstr.Bases) ||
+		!equality.Semantic.DeepEqual(oldCpdstr.Spec, cpdstr.Spec) {
+		return nil, errors.New("ClusterProjectDevelopmentStreamTemplateRevision is immutable")
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements admission.Validator
+func (v *ClusterProjectDevelopmentStreamTemplateRevisionCustomValidator) ValidateDelete(
+	ctx context.Context, cpdstr *projctlv1beta1.ClusterProjectDevelopmentStreamTemplateRevision,
+) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+// SetupProjectDevelopmentStreamWebhookWithManager registers the webhook for
+// ProjectDevelopmentStream in the manager.
+func SetupProjectDevelopmentStreamWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &projctlv1beta1.ProjectDevelopmentStream{}).
+		WithValidator(&ProjectDevelopmentStreamCustomValidator{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-projctl-konflux-dev-v1beta1-projectdevelopmentstream,mutating=false,failurePolicy=fail,sideEffects=None,groups=projctl.konflux.dev,resources=projectdevelopmentstreams,verbs=create;update,versions=v1beta1,name=vprojectdevelopmentstream-v1beta1.kb.io,admissionReviewVersions=v1
+
+// ProjectDevelopmentStreamCustomValidator rejects development streams that
+// resources cannot be generated for from their template, e.g. because they
+// give values to variables the template does not define or omit values for
+// variables that have no defaults. Resources are generated the same way the
+// controller does, so the webhook and the controller always agree.
+type ProjectDevelopmentStreamCustomValidator struct {
+	Client client.Reader
+	// If set, used for reading the ConfigMaps and Secrets template values are
+	// taken from, so that their contents do not get cached
+	APIReader client.Reader
+}
+
+var _ admission.Validator[*projctlv1beta1.ProjectDevelopmentStream] = &ProjectDevelopmentStreamCustomValidator{}
+
+// ValidateCreate implements admission.Validator
+func (v *ProjectDevelopmentStreamCustomValidator) ValidateCreate(
+	ctx context.Context, pds *projctlv1beta1.ProjectDevelopmentStream,
+) (admission.Warnings, error) {
+	return v.validate(ctx, pds)
+}
+
+// ValidateUpdate implements admission.Validator
+func (v *ProjectDevelopmentStreamCustomValidator) ValidateUpdate(
+	ctx context.Context, oldPds, pds *projctlv1beta1.ProjectDevelopmentStream,
+) (admission.Warnings, error) {
+	// Do not block metadata changes, e.g. to finalizers or owner references,
+	// of streams that became invalid due to changes to their template
+	if equality.Semantic.DeepEqual(oldPds.Spec, pds.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, pds)
+}
+
+// ValidateDelete implements admission.Validator
+func (v *ProjectDevelopmentStreamCustomValidator) ValidateDelete(
+	ctx context.Context, pds *projctlv1beta1.ProjectDevelopmentStream,
+) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ProjectDevelopmentStreamCustomValidator) validate(
+	ctx context.Context, pds *projctlv1beta1.ProjectDevelopmentStream,
+) (admission.Warnings, error) {
+	if pds.Spec.Template == nil {
+		if len(pds.Spec.Patches) > 0 {
+			return admission.Warnings{"patches are ignored since the stream does not use a template"}, nil
+		}
+		return nil, nil
+	}
+	templateName := pds.Spec.Template.Name
+	templateKind := template.RefKind(pds.Spec.Template)
+	pdst, err := template.Get(ctx, v.Client, pds)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The template may be created after the stream, so we let the
+			// controller report the issue
+			return admission.Warnings{fmt.Sprintf(
+				"%s '%s' not found, template values not checked", templateKind, templateName,
+			)}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch %s '%s': %w", templateKind, templateName, err)
+	}
+	valueReader := v.APIReader
+	if valueReader == nil {
+		valueReader = v.Client
+	}
+	values, err := template.ResolveValues(ctx, valueReader, pds)
+	if err != nil {
+		// The ConfigMaps and Secrets may be created after the stream, so we
+		// let the controller report the issue
+		return admission.Warnings{fmt.Sprintf("template values not checked: %v", err)}, nil
+	}
+	rendered := pds.DeepCopy()
+	rendered.Spec.Template.Values = values
+	project, err := template.GetProject(ctx, v.Client, pds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Project '%s': %w", pds.Spec.Project, err)
+	}
+	if _, _, err := template.MkResources(*rendered, *pdst, project); err != nil {
+		return nil, fmt.Errorf(
+			"failed to generate resources from %s '%s': %w", templateKind, templateName, err,
+		)
+	}
+	return nil, nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("ProjectDevelopmentStream Webhook", func() {
+	ctx := context.Background()
+
+	var (
+		validator ProjectDevelopmentStreamCustomValidator
+		pds       *projctlv1beta1.ProjectDevelopmentStream
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(projctlv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		pdst := &projctlv1beta1.ProjectDevelopmentStreamTemplate{}
+		sampleResource("projctl_v1beta1_projectdevelopmentstreamtemplate.yaml", pdst)
+		cpdst := &projctlv1beta1.ClusterProjectDevelopmentStreamTemplate{}
+		sampleResource("projctl_v1beta1_clusterprojectdevelopmentstreamtemplate.yaml", cpdst)
+		cpdst.SetNamespace("")
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "versions"},
+			Data:       map[string]string{"current": "1.0.0"},
+		}
+		validator = ProjectDevelopmentStreamCustomValidator{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pdst, cpdst, cm).Build(),
+		}
+
+		pds = &projctlv1beta1.ProjectDevelopmentStream{}
+		sampleResource("projctl_v1beta1_projectdevelopmentstream_w_template_vars.yaml", pds)
+	})
+
+	It("admits a stream with valid template values", func() {
+		warnings, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("admits a stream without a template", func() {
+		pds.Spec.Template = nil
+		_, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("warns about patches of streams without a template", func() {
+		pds.Spec.Template = nil
+		pds.Spec.Patches = []projctlv1beta1.ProjectDevelopmentStreamPatch{{
+			Target: projctlv1beta1.ProjectDevelopmentStreamPatchTarget{Kind: "Application", Name: "app"},
+			Patch:  "spec: {}",
+		}}
+		warnings, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring("patches are ignored")))
+	})
+
+	It("rejects patches that do not apply to the generated resources", func() {
+		pds.Spec.Patches = []projctlv1beta1.ProjectDevelopmentStreamPatch{{
+			Target: projctlv1beta1.ProjectDevelopmentStreamPatchTarget{Kind: "Application", Name: "no-such-app"},
+			Patch:  "spec: {}",
+		}}
+		_, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).To(MatchError(ContainSubstring("targets Application 'no-such-app', which is not generated")))
+	})
+
+	It("warns about templates that do not exist", func() {
+		pds.Spec.Template.Name = "no-such-template"
+		warnings, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring("'no-such-template' not found")))
+	})
+
+	It("checks values against cluster templates", func() {
+		pds.Spec.Template = &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateRef{
+			Kind: projctlv1beta1.TemplateKindCluster,
+			Name: "cluster-pdst-sample",
+		}
+		_, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).To(MatchError(ContainSubstring(
+			"failed to generate resources from ClusterProjectDevelopmentStreamTemplate 'cluster-pdst-sample'",
+		)))
+
+		pds.Spec.Template.Values = []projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+			{Name: "version", Value: "1.0.0"},
+		}
+		_, err = validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("does not look up namespaced templates for cluster template references", func() {
+		pds.Spec.Template.Kind = projctlv1beta1.TemplateKindCluster
+		warnings, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring("ClusterProjectDevelopmentStreamTemplate")))
+	})
+
+	It("rejects values for variables the template does not define", func() {
+		pds.Spec.Template.Values = append(
+			pds.Spec.Template.Values,
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "nosuchvar", Value: "foo"},
+		)
+		_, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).To(MatchError(ContainSubstring(
+			"a value was given for undefined template variable 'nosuchvar'",
+		)))
+	})
+
+	It("rejects streams missing values for variables without defaults", func() {
+		pds.Spec.Template.Values = nil
+		_, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).To(MatchError(ContainSubstring(
+			"template variable 'version' is missing a value and default not defined",
+		)))
+	})
+
+	It("rejects values that generate invalid resources", func() {
+		pds.Spec.Template.Values = append(
+			pds.Spec.Template.Values,
+			projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{Name: "versionName", Value: "1.0.0"},
+		)
+		_, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).To(MatchError(ContainSubstring("invalid resource name value 'cool-app-1.0.0'")))
+	})
+
+	It("checks values read from ConfigMaps", func() {
+		pds.Spec.Template.Values[0] = projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValue{
+			Name: "version",
+			ValueFrom: &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "versions"},
+					Key:                  "current",
+				},
+			},
+		}
+		warnings, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("warns about ConfigMaps that do not exist", func() {
+		pds.Spec.Template.Values[0].Value = ""
+		pds.Spec.Template.Values[0].ValueFrom = &projctlv1beta1.ProjectDevelopmentStreamSpecTemplateValueSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "no-such-cm"},
+				Key:                  "current",
+			},
+		}
+		warnings, err := validator.ValidateCreate(ctx, pds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring("template values not checked")))
+	})
+
+	It("validates stream spec changes on update", func() {
+		oldPds := pds.DeepCopy()
+		pds.Spec.Template.Values = nil
+		_, err := validator.ValidateUpdate(ctx, oldPds, pds)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("admits metadata changes to streams that became invalid", func() {
+		pds.Spec.Template.Values = nil
+		oldPds := pds.DeepCopy()
+		pds.SetFinalizers([]string{"projctl.konflux.dev/finalizer"})
+		_, err := validator.ValidateUpdate(ctx, oldPds, pds)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+// SetupProjectDevelopmentStreamTemplateWebhookWithManager registers the
+// webhook for ProjectDevelopmentStreamTemplate in the manager.
+func SetupProjectDevelopmentStreamTemplateWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &projctlv1beta1.ProjectDevelopmentStreamTemplate{}).
+		WithValidator(&ProjectDevelopmentStreamTemplateCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-projctl-konflux-dev-v1beta1-projectdevelopmentstreamtemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplates,verbs=create;update,versions=v1beta1,name=vprojectdevelopmentstreamtemplate-v1beta1.kb.io,admissionReviewVersions=v1
+
+// ProjectDevelopmentStreamTemplateCustomValidator rejects templates that
+// resources cannot be generated from, regardless of the variable values given
+// to them, i.e. templates that include unsupported resource types or
+// templated fields or variable defaults that fail to parse. Templates are
+// checked with the templates they extend merged into them. Other issues found
+// in the template are returned as warnings.
+type ProjectDevelopmentStreamTemplateCustomValidator struct {
+	// Used for fetching the templates a template extends
+	Client client.Reader
+}
+
+var _ admission.Validator[*projctlv1beta1.ProjectDevelopmentStreamTemplate] = &ProjectDevelopmentStreamTemplateCustomValidator{}
+
+// ValidateCreate implements admission.Validator
+func (v *ProjectDevelopmentStreamTemplateCustomValidator) ValidateCreate(
+	ctx context.Context, pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (admission.Warnings, error) {
+	return validateTemplate(ctx, v.Client, "ProjectDevelopmentStreamTemplate", pdst)
+}
+
+// ValidateUpdate implements admission.Validator
+func (v *ProjectDevelopmentStreamTemplateCustomValidator) ValidateUpdate(
+	ctx context.Context, oldPdst, pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (admission.Warnings, error) {
+	// Do not block metadata changes to templates that were created before the
+	// webhook was deployed
+	if equality.Semantic.DeepEqual(oldPdst.Spec, pdst.Spec) {
+		return nil, nil
+	}
+	return validateTemplate(ctx, v.Client, "ProjectDevelopmentStreamTemplate", pdst)
+}
+
+// ValidateDelete implements admission.Validator
+func (v *ProjectDevelopmentStreamTemplateCustomValidator) ValidateDelete(
+	ctx context.Context, pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate the given template of the given kind, with the templates it
+// extends merged into it, returning issues that do not prevent generating
+// resources from the template as warnings. Cluster templates are expected to
+// be given converted into templates with no namespace.
+func validateTemplate(
+	ctx context.Context, c client.Reader, kind string, pdst *projctlv1beta1.ProjectDevelopmentStreamTemplate,
+) (admission.Warnings, error) {
+	resolved, err := template.Resolve(ctx, c, pdst)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The extended templates may be created after the template, so we
+			// let the controller report the issue
+			return admission.Warnings{fmt.Sprintf("%v, template not checked", err)}, nil
+		}
+		return nil, fmt.Errorf("invalid %s: %w", kind, err)
+	}
+	var warnings admission.Warnings
+	var errs []error
+	for _, err := range splitErrors(template.Validate(*resolved)) {
+		if errors.Is(err, template.ErrUndefinedVariable) || errors.Is(err, template.ErrDuplicateVariable) {
+			warnings = append(warnings, err.Error())
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return warnings, fmt.Errorf("invalid %s: %w", kind, errors.Join(errs...))
+	}
+	return warnings, nil
+}
+
+// Split an error created with errors.Join back into the errors it was made of
+func splitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
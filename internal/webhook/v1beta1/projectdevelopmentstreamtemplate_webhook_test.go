@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+	"github.com/konflux-ci/project-controller/internal/template"
+)
+
+var _ = Describe("ProjectDevelopmentStreamTemplate Webhook", func() {
+	ctx := context.Background()
+
+	var (
+		validator ProjectDevelopmentStreamTemplateCustomValidator
+		pdst      *projctlv1beta1.ProjectDevelopmentStreamTemplate
+	)
+
+	BeforeEach(func() {
+		validator = ProjectDevelopmentStreamTemplateCustomValidator{}
+		pdst = &projctlv1beta1.ProjectDevelopmentStreamTemplate{}
+		sampleResource("projctl_v1beta1_projectdevelopmentstreamtemplate.yaml", pdst)
+	})
+
+	It("admits a valid template", func() {
+		warnings, err := validator.ValidateCreate(ctx, pdst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("rejects templates with unsupported resource types", func() {
+		pdst.Spec.Resources[1].SetKind("Snapshot")
+		_, err := validator.ValidateCreate(ctx, pdst)
+		Expect(err).To(MatchError(ContainSubstring("unsupported resource type in template")))
+	})
+
+	It("rejects templates with templated fields that fail to parse", func() {
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object, "Cool App {{.version", "spec", "displayName",
+		)).To(Succeed())
+		_, err := validator.ValidateCreate(ctx, pdst)
+		Expect(err).To(MatchError(ContainSubstring("invalid template in field 'spec.displayName'")))
+	})
+
+	It("rejects templates with variable defaults that fail to parse", func() {
+		pdst.Spec.Variables[1].DefaultValue = new("{{hyphenize .version")
+		_, err := validator.ValidateCreate(ctx, pdst)
+		Expect(err).To(MatchError(ContainSubstring("invalid default value for template variable 'versionName'")))
+	})
+
+	It("warns about references to undefined variables", func() {
+		Expect(unstructured.SetNestedField(
+			pdst.Spec.Resources[0].Object, "Cool App {{.nosuchvar}}", "spec", "displayName",
+		)).To(Succeed())
+		warnings, err := validator.ValidateCreate(ctx, pdst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring("reference to undefined variable(s): nosuchvar")))
+	})
+
+	It("validates template spec changes on update", func() {
+		oldPdst := pdst.DeepCopy()
+		pdst.Spec.Resources[1].SetKind("Snapshot")
+		_, err := validator.ValidateUpdate(ctx, oldPdst, pdst)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("extending other templates", func() {
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(projctlv1beta1.AddToScheme(scheme)).To(Succeed())
+			base := &projctlv1beta1.ProjectDevelopmentStreamTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: pdst.GetNamespace(), Name: "base"},
+				Spec:       projctlv1beta1.ProjectDevelopmentStreamTemplateSpec{Variables: pdst.Spec.Variables},
+			}
+			validator.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(base).Build()
+			pdst.Spec.Variables = nil
+		})
+
+		It("validates templates with the templates they extend merged into them", func() {
+			pdst.Spec.Extends = []projctlv1beta1.TemplateReference{{Name: "base"}}
+			warnings, err := validator.ValidateCreate(ctx, pdst)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("rejects templates that extend each other in a cycle", func() {
+			pdst.Spec.Extends = []projctlv1beta1.TemplateReference{{Name: pdst.GetName()}}
+			_, err := validator.ValidateCreate(ctx, pdst)
+			Expect(err).To(MatchError(template.ErrExtendsCycle))
+		})
+
+		It("warns about missing extended templates", func() {
+			pdst.Spec.Extends = []projctlv1beta1.TemplateReference{{Name: "nosuchtemplate"}}
+			warnings, err := validator.ValidateCreate(ctx, pdst)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("not found, template not checked")))
+		})
+	})
+
+	It("admits metadata changes to invalid templates", func() {
+		pdst.Spec.Resources[1].SetKind("Snapshot")
+		oldPdst := pdst.DeepCopy()
+		pdst.SetLabels(map[string]string{"foo": "bar"})
+		_, err := validator.ValidateUpdate(ctx, oldPdst, pdst)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
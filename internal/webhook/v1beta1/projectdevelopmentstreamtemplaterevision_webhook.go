@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+// SetupProjectDevelopmentStreamTemplateRevisionWebhookWithManager registers
+// the webhook for ProjectDevelopmentStreamTemplateRevision in the manager.
+func SetupProjectDevelopmentStreamTemplateRevisionWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &projctlv1beta1.ProjectDevelopmentStreamTemplateRevision{}).
+		WithValidator(&ProjectDevelopmentStreamTemplateRevisionCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-projctl-konflux-dev-v1beta1-projectdevelopmentstreamtemplaterevision,mutating=false,failurePolicy=fail,sideEffects=None,groups=projctl.konflux.dev,resources=projectdevelopmentstreamtemplaterevisions,verbs=update,versions=v1beta1,name=vprojectdevelopmentstreamtemplaterevision-v1beta1.kb.io,admissionReviewVersions=v1
+
+// ProjectDevelopmentStreamTemplateRevisionCustomValidator rejects changes to
+// the revision, bases and spec of template revisions, which are meant to be
+// immutable snapshots of templates that streams may be pinned to. Metadata
+// changes are allowed so that revisions can still be labeled or garbage
+// collected.
+type ProjectDevelopmentStreamTemplateRevisionCustomValidator struct{}
+
+var _ admission.Validator[*projctlv1beta1.ProjectDevelopmentStreamTemplateRevision] = &ProjectDevelopmentStreamTemplateRevisionCustomValidator{}
+
+// ValidateCreate implements admission.Validator
+func (v *ProjectDevelopmentStreamTemplateRevisionCustomValidator) ValidateCreate(
+	ctx context.Context, pdstr *projctlv1beta1.ProjectDevelopmentStreamTemplateRevision,
+) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate implements admission.Validator
+func (v *ProjectDevelopmentStreamTemplateRevisionCustomValidator) ValidateUpdate(
+	ctx context.Context, oldPdstr, pdstr *projctlv1beta1.ProjectDevelopmentStreamTemplateRevision,
+) (admission.Warnings, error) {
+	if oldPdstr.Revision != pdstr.Revision ||
+		!equality.Semantic.DeepEqual(oldPdstr.Bases, pdstr.Bases) ||
+		!equality.Semantic.DeepEqual(oldPdstr.Spec, pdstr.Spec) {
+		return nil, errors.New("ProjectDevelopmentStreamTemplateRevision is immutable")
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements admission.Validator
+func (v *ProjectDevelopmentStreamTemplateRevisionCustomValidator) ValidateDelete(
+	ctx context.Context, pdstr *projctlv1beta1.ProjectDevelopmentStreamTemplateRevision,
+) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	projctlv1beta1 "github.com/konflux-ci/project-controller/api/v1beta1"
+)
+
+var _ = Describe("ProjectDevelopmentStreamTemplateRevision Webhook", func() {
+	ctx := context.Background()
+
+	var (
+		validator ProjectDevelopmentStreamTemplateRevisionCustomValidator
+		pdstr     *projctlv1beta1.ProjectDevelopmentStreamTemplateRevision
+	)
+
+	BeforeEach(func() {
+		validator = ProjectDevelopmentStreamTemplateRevisionCustomValidator{}
+		pdstr = &projctlv1beta1.ProjectDevelopmentStreamTemplateRevision{Revision: 1}
+		sampleResource("projctl_v1beta1_projectdevelopmentstreamtemplate.yaml", pdstr)
+	})
+
+	It("admits metadata changes", func() {
+		old := pdstr.DeepCopy()
+		pdstr.SetLabels(map[string]string{"foo": "bar"})
+		_, err := validator.ValidateUpdate(ctx, old, pdstr)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects spec changes", func() {
+		old := pdstr.DeepCopy()
+		pdstr.Spec.Variables = nil
+		_, err := validator.ValidateUpdate(ctx, old, pdstr)
+		Expect(err).To(MatchError("ProjectDevelopmentStreamTemplateRevision is immutable"))
+	})
+
+	It("rejects changes to the recorded extended templates", func() {
+		old := pdstr.DeepCopy()
+		pdstr.Bases = []projctlv1beta1.TemplateBase{{Name: "base", Generation: 2}}
+		_, err := validator.ValidateUpdate(ctx, old, pdstr)
+		Expect(err).To(MatchError("ProjectDevelopmentStreamTemplateRevision is immutable"))
+	})
+
+	It("rejects revision changes", func() {
+		old := pdstr.DeepCopy()
+		pdstr.Revision = 2
+		_, err := validator.ValidateUpdate(ctx, old, pdstr)
+		Expect(err).To(MatchError("ProjectDevelopmentStreamTemplateRevision is immutable"))
+	})
+})